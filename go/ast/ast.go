@@ -0,0 +1,672 @@
+package ast
+
+import (
+	"bytes"
+	"gorb/token"
+	"strings"
+)
+
+type Node interface {
+	TokenLiteral() string
+	String() string
+	Pos() token.Pos
+}
+
+type Statement interface {
+	Node
+	statementNode()
+}
+
+type Expression interface {
+	Node
+	expressionNode()
+}
+
+// Comment is a single `//` or `/* */` comment. Text holds the raw source,
+// delimiters included, so a printer can reproduce it exactly.
+type Comment struct {
+	Token   token.Token
+	Text    string
+	EndLine int // the line the comment ends on; > Token.Pos.Ln for a block comment spanning multiple lines
+}
+
+func (c *Comment) Pos() token.Pos { return c.Token.Pos }
+
+// CommentGroup is a run of comments with no blank line between them, the
+// unit comments are attached to AST nodes as.
+type CommentGroup struct {
+	List []*Comment
+}
+
+func (g *CommentGroup) Pos() token.Pos {
+	if len(g.List) > 0 {
+		return g.List[0].Pos()
+	}
+	return token.Pos{}
+}
+
+// String reproduces the comment group's source text, one comment per line,
+// so a future formatter/printer can round-trip it faithfully.
+func (g *CommentGroup) String() string {
+	lines := make([]string, len(g.List))
+	for i, c := range g.List {
+		lines[i] = c.Text
+	}
+	return strings.Join(lines, "\n")
+}
+
+type Program struct {
+	Statements []Statement
+
+	// Comments holds every CommentGroup the parser collected in ParseComments
+	// mode that wasn't attached as a Doc or trailing Comment elsewhere, e.g. a
+	// comment on its own line with blank lines on both sides. Empty unless
+	// ParseComments was set.
+	Comments []*CommentGroup
+}
+
+func (p *Program) TokenLiteral() string {
+	if len(p.Statements) > 0 {
+		return p.Statements[0].TokenLiteral()
+	}
+	return ""
+}
+
+func (p *Program) Pos() token.Pos {
+	if len(p.Statements) > 0 {
+		return p.Statements[0].Pos()
+	}
+	return token.Pos{}
+}
+
+func (p *Program) String() string {
+	var out bytes.Buffer
+
+	for _, s := range p.Statements {
+		out.WriteString(s.String())
+	}
+
+	return out.String()
+}
+
+// statements
+
+type ReturnStatement struct {
+	Token       token.Token
+	ReturnValue Expression
+}
+
+func (rs *ReturnStatement) statementNode()       {}
+func (rs *ReturnStatement) TokenLiteral() string { return rs.Token.Literal }
+func (rs *ReturnStatement) Pos() token.Pos       { return rs.Token.Pos }
+func (rs *ReturnStatement) String() string {
+	var out bytes.Buffer
+
+	out.WriteString(rs.TokenLiteral() + " ")
+
+	if rs.ReturnValue != nil {
+		out.WriteString(rs.ReturnValue.String())
+	}
+
+	out.WriteString(";")
+
+	return out.String()
+}
+
+type DeclarationStatement struct {
+	Token          token.Token
+	Name           *Identifier
+	TypeAnnotation *TypeAnnotation
+	Value          Expression
+
+	// Doc is the comment group immediately preceding this declaration, if
+	// any. Only populated in ParseComments mode.
+	Doc *CommentGroup
+}
+
+func (ds *DeclarationStatement) statementNode()       {}
+func (ds *DeclarationStatement) TokenLiteral() string { return ds.Token.Literal }
+func (ds *DeclarationStatement) Pos() token.Pos       { return ds.Token.Pos }
+func (ds *DeclarationStatement) String() string {
+	var out bytes.Buffer
+
+	out.WriteString(ds.TokenLiteral() + " ")
+	out.WriteString(ds.Name.String())
+	out.WriteString(" = ")
+
+	if ds.Value != nil {
+		out.WriteString(ds.Value.String())
+	}
+
+	out.WriteString(";")
+
+	return out.String()
+}
+
+// AssignStatement updates a name that was already bound with let, e.g.
+// `i = i + 1;`, as opposed to DeclarationStatement which introduces one.
+type AssignStatement struct {
+	Token token.Token // the identifier token
+	Name  *Identifier
+	Value Expression
+}
+
+func (as *AssignStatement) statementNode()       {}
+func (as *AssignStatement) TokenLiteral() string { return as.Token.Literal }
+func (as *AssignStatement) Pos() token.Pos       { return as.Token.Pos }
+func (as *AssignStatement) String() string {
+	var out bytes.Buffer
+
+	out.WriteString(as.Name.String())
+	out.WriteString(" = ")
+
+	if as.Value != nil {
+		out.WriteString(as.Value.String())
+	}
+
+	out.WriteString(";")
+
+	return out.String()
+}
+
+type ExpressionStatement struct {
+	Token      token.Token
+	Expression Expression
+
+	// Comment is a trailing same-line comment following this statement, if
+	// any. Only populated in ParseComments mode.
+	Comment *CommentGroup
+}
+
+func (es *ExpressionStatement) statementNode()       {}
+func (es *ExpressionStatement) TokenLiteral() string { return es.Token.Literal }
+func (es *ExpressionStatement) Pos() token.Pos       { return es.Token.Pos }
+func (es *ExpressionStatement) String() string {
+	if es.Expression != nil {
+		return es.Expression.String()
+	}
+	return ""
+}
+
+type BlockStatement struct {
+	Token      token.Token
+	Statements []Statement
+}
+
+func (bs *BlockStatement) statementNode()       {}
+func (bs *BlockStatement) TokenLiteral() string { return bs.Token.Literal }
+func (bs *BlockStatement) Pos() token.Pos       { return bs.Token.Pos }
+func (bs *BlockStatement) String() string {
+	var out bytes.Buffer
+
+	for _, s := range bs.Statements {
+		out.WriteString(s.String())
+	}
+
+	return out.String()
+}
+
+type WhileStatement struct {
+	Token     token.Token
+	Condition Expression
+	Body      *BlockStatement
+}
+
+func (ws *WhileStatement) statementNode()       {}
+func (ws *WhileStatement) TokenLiteral() string { return ws.Token.Literal }
+func (ws *WhileStatement) Pos() token.Pos       { return ws.Token.Pos }
+func (ws *WhileStatement) String() string {
+	var out bytes.Buffer
+
+	out.WriteString("while")
+	out.WriteString(ws.Condition.String())
+	out.WriteString(" ")
+	out.WriteString(ws.Body.String())
+
+	return out.String()
+}
+
+// ForStatement is the classic three-clause C-style for loop: Init and Post
+// run once and after each iteration, Condition gates it, all of them
+// optional (a bare `for (;;) { ... }` loops forever).
+type ForStatement struct {
+	Token     token.Token
+	Init      Statement
+	Condition Expression
+	Post      Statement
+	Body      *BlockStatement
+}
+
+func (fs *ForStatement) statementNode()       {}
+func (fs *ForStatement) TokenLiteral() string { return fs.Token.Literal }
+func (fs *ForStatement) Pos() token.Pos       { return fs.Token.Pos }
+func (fs *ForStatement) String() string {
+	var out bytes.Buffer
+
+	out.WriteString("for (")
+	if fs.Init != nil {
+		out.WriteString(fs.Init.String())
+	}
+	out.WriteString(" ")
+	if fs.Condition != nil {
+		out.WriteString(fs.Condition.String())
+	}
+	out.WriteString("; ")
+	if fs.Post != nil {
+		out.WriteString(fs.Post.String())
+	}
+	out.WriteString(") ")
+	out.WriteString(fs.Body.String())
+
+	return out.String()
+}
+
+type BreakStatement struct {
+	Token token.Token
+}
+
+func (bs *BreakStatement) statementNode()       {}
+func (bs *BreakStatement) TokenLiteral() string { return bs.Token.Literal }
+func (bs *BreakStatement) Pos() token.Pos       { return bs.Token.Pos }
+func (bs *BreakStatement) String() string       { return "break;" }
+
+type ContinueStatement struct {
+	Token token.Token
+}
+
+func (cs *ContinueStatement) statementNode()       {}
+func (cs *ContinueStatement) TokenLiteral() string { return cs.Token.Literal }
+func (cs *ContinueStatement) Pos() token.Pos       { return cs.Token.Pos }
+func (cs *ContinueStatement) String() string       { return "continue;" }
+
+// StructDeclaration declares a named record type, e.g.
+// `struct Point { x: int, y: int }`. It has no runtime value of its own; the
+// evaluator registers a *object.Struct descriptor under Name in the
+// environment, the same way a DeclarationStatement registers a value.
+type StructDeclaration struct {
+	Token      token.Token
+	Name       *Identifier
+	Fields     []*Identifier // field names, in declared order
+	FieldTypes []*TypeAnnotation
+
+	// Doc is the comment group immediately preceding this declaration, if
+	// any. Only populated in ParseComments mode.
+	Doc *CommentGroup
+}
+
+func (sd *StructDeclaration) statementNode()       {}
+func (sd *StructDeclaration) TokenLiteral() string { return sd.Token.Literal }
+func (sd *StructDeclaration) Pos() token.Pos       { return sd.Token.Pos }
+func (sd *StructDeclaration) String() string {
+	var out bytes.Buffer
+
+	fields := []string{}
+	for i, f := range sd.Fields {
+		field := f.String()
+		if i < len(sd.FieldTypes) && sd.FieldTypes[i] != nil {
+			field += ": " + sd.FieldTypes[i].String()
+		}
+		fields = append(fields, field)
+	}
+
+	out.WriteString("struct ")
+	out.WriteString(sd.Name.String())
+	out.WriteString(" { ")
+	out.WriteString(strings.Join(fields, ", "))
+	out.WriteString(" }")
+
+	return out.String()
+}
+
+// expressions
+
+type Identifier struct {
+	Token token.Token
+	Value string
+}
+
+func (i *Identifier) expressionNode()      {}
+func (i *Identifier) TokenLiteral() string { return i.Token.Literal }
+func (i *Identifier) Pos() token.Pos       { return i.Token.Pos }
+func (i *Identifier) String() string       { return i.Value }
+
+type IndexExpression struct {
+	Token token.Token
+	Left  Expression
+	Index Expression
+}
+
+func (ie *IndexExpression) expressionNode()      {}
+func (ie *IndexExpression) TokenLiteral() string { return ie.Token.Literal }
+func (ie *IndexExpression) Pos() token.Pos       { return ie.Token.Pos }
+func (ie *IndexExpression) String() string {
+	var out bytes.Buffer
+
+	out.WriteString("(")
+	out.WriteString(ie.Left.String())
+	out.WriteString("[")
+	out.WriteString(ie.Index.String())
+	out.WriteString("])")
+
+	return out.String()
+}
+
+// FieldAccessExpression is dot-access on a struct instance, e.g. `p.x`.
+type FieldAccessExpression struct {
+	Token token.Token // the '.' token
+	Left  Expression
+	Field *Identifier
+}
+
+func (fa *FieldAccessExpression) expressionNode()      {}
+func (fa *FieldAccessExpression) TokenLiteral() string { return fa.Token.Literal }
+func (fa *FieldAccessExpression) Pos() token.Pos       { return fa.Token.Pos }
+func (fa *FieldAccessExpression) String() string {
+	var out bytes.Buffer
+
+	out.WriteString(fa.Left.String())
+	out.WriteString(".")
+	out.WriteString(fa.Field.String())
+
+	return out.String()
+}
+
+type CallExpression struct {
+	Token     token.Token
+	Function  Expression
+	Arguments []Expression
+}
+
+func (ce *CallExpression) expressionNode()      {}
+func (ce *CallExpression) TokenLiteral() string { return ce.Token.Literal }
+func (ce *CallExpression) Pos() token.Pos       { return ce.Token.Pos }
+func (ce *CallExpression) String() string {
+	var out bytes.Buffer
+
+	args := []string{}
+	for _, a := range ce.Arguments {
+		args = append(args, a.String())
+	}
+
+	out.WriteString(ce.Function.String())
+	out.WriteString("(")
+	out.WriteString(strings.Join(args, ", "))
+	out.WriteString(")")
+
+	return out.String()
+}
+
+type IfExpression struct {
+	Token       token.Token
+	Condition   Expression
+	Consequence *BlockStatement
+	Alternative *BlockStatement
+}
+
+func (ie *IfExpression) expressionNode()      {}
+func (ie *IfExpression) TokenLiteral() string { return ie.Token.Literal }
+func (ie *IfExpression) Pos() token.Pos       { return ie.Token.Pos }
+func (ie *IfExpression) String() string {
+	var out bytes.Buffer
+
+	out.WriteString("if")
+	out.WriteString(ie.Condition.String())
+	out.WriteString(" ")
+	out.WriteString(ie.Consequence.String())
+
+	if ie.Alternative != nil {
+		out.WriteString("else ")
+		out.WriteString(ie.Alternative.String())
+	}
+
+	return out.String()
+}
+
+type UnaryExpression struct {
+	Token    token.Token
+	Operator token.TokenType
+	Right    Expression
+}
+
+func (ue *UnaryExpression) expressionNode()      {}
+func (ue *UnaryExpression) TokenLiteral() string { return ue.Token.Literal }
+func (ue *UnaryExpression) Pos() token.Pos       { return ue.Token.Pos }
+func (ue *UnaryExpression) String() string {
+	var out bytes.Buffer
+
+	out.WriteString("(")
+	out.WriteString(string(ue.Operator))
+	out.WriteString(ue.Right.String())
+	out.WriteString(")")
+
+	return out.String()
+}
+
+type BinaryExpression struct {
+	Token    token.Token
+	Left     Expression
+	Operator token.TokenType
+	Right    Expression
+}
+
+func (be *BinaryExpression) expressionNode()      {}
+func (be *BinaryExpression) TokenLiteral() string { return be.Token.Literal }
+func (be *BinaryExpression) Pos() token.Pos       { return be.Token.Pos }
+func (be *BinaryExpression) String() string {
+	var out bytes.Buffer
+
+	out.WriteString("(")
+	out.WriteString(be.Left.String())
+	out.WriteString(" " + string(be.Operator) + " ")
+	out.WriteString(be.Right.String())
+	out.WriteString(")")
+
+	return out.String()
+}
+
+// type annotations
+
+// TypeAnnotation is the syntax for an optional type hint, e.g. the `int` in
+// `let x: int = 1` or `a: string` in a function parameter list. It only
+// carries the written name; resolving it to a semantic type is the
+// typechecker's job.
+type TypeAnnotation struct {
+	Token token.Token
+	Name  string
+}
+
+func (ta *TypeAnnotation) TokenLiteral() string { return ta.Token.Literal }
+func (ta *TypeAnnotation) Pos() token.Pos       { return ta.Token.Pos }
+func (ta *TypeAnnotation) String() string       { return ta.Name }
+
+// literals
+
+type FunctionLiteral struct {
+	Token      token.Token
+	Parameters []*Identifier
+	ParamTypes []*TypeAnnotation // aligned with Parameters, nil entry if unannotated
+	ReturnType *TypeAnnotation
+	Body       *BlockStatement
+
+	// Doc is the comment group immediately preceding this function literal,
+	// if any. Only populated in ParseComments mode.
+	Doc *CommentGroup
+}
+
+func (fl *FunctionLiteral) expressionNode()      {}
+func (fl *FunctionLiteral) TokenLiteral() string { return fl.Token.Literal }
+func (fl *FunctionLiteral) Pos() token.Pos       { return fl.Token.Pos }
+func (fl *FunctionLiteral) String() string {
+	var out bytes.Buffer
+
+	params := []string{}
+	for _, p := range fl.Parameters {
+		params = append(params, p.String())
+	}
+
+	out.WriteString(fl.TokenLiteral())
+	out.WriteString("(")
+	out.WriteString(strings.Join(params, ", "))
+	out.WriteString(") ")
+	out.WriteString(fl.Body.String())
+
+	return out.String()
+}
+
+// MacroLiteral declares a macro, e.g. `macro(a, b) { quote(a + b); }`.
+// Unlike FunctionLiteral, its parameters are untyped: macros operate on
+// unevaluated AST fragments, not runtime values.
+type MacroLiteral struct {
+	Token      token.Token
+	Parameters []*Identifier
+	Body       *BlockStatement
+}
+
+func (ml *MacroLiteral) expressionNode()      {}
+func (ml *MacroLiteral) TokenLiteral() string { return ml.Token.Literal }
+func (ml *MacroLiteral) Pos() token.Pos       { return ml.Token.Pos }
+func (ml *MacroLiteral) String() string {
+	var out bytes.Buffer
+
+	params := []string{}
+	for _, p := range ml.Parameters {
+		params = append(params, p.String())
+	}
+
+	out.WriteString(ml.TokenLiteral())
+	out.WriteString("(")
+	out.WriteString(strings.Join(params, ", "))
+	out.WriteString(") ")
+	out.WriteString(ml.Body.String())
+
+	return out.String()
+}
+
+// QuoteExpression captures Node, one of its own subexpressions, without
+// evaluating it, so a macro can hand the unevaluated AST back to
+// ExpandMacros to splice into the caller.
+type QuoteExpression struct {
+	Token token.Token
+	Node  Node
+}
+
+func (qe *QuoteExpression) expressionNode()      {}
+func (qe *QuoteExpression) TokenLiteral() string { return qe.Token.Literal }
+func (qe *QuoteExpression) Pos() token.Pos       { return qe.Token.Pos }
+func (qe *QuoteExpression) String() string {
+	return qe.TokenLiteral() + "(" + qe.Node.String() + ")"
+}
+
+type BooleanLiteral struct {
+	Token token.Token
+	Value bool
+}
+
+func (bl *BooleanLiteral) expressionNode()      {}
+func (bl *BooleanLiteral) TokenLiteral() string { return bl.Token.Literal }
+func (bl *BooleanLiteral) Pos() token.Pos       { return bl.Token.Pos }
+func (bl *BooleanLiteral) String() string       { return bl.Token.Literal }
+
+type IntegerLiteral struct {
+	Token token.Token
+	Value int64
+}
+
+func (il *IntegerLiteral) expressionNode()      {}
+func (il *IntegerLiteral) TokenLiteral() string { return il.Token.Literal }
+func (il *IntegerLiteral) Pos() token.Pos       { return il.Token.Pos }
+func (il *IntegerLiteral) String() string       { return il.Token.Literal }
+
+type FloatLiteral struct {
+	Token token.Token
+	Value float64
+}
+
+func (fl *FloatLiteral) expressionNode()      {}
+func (fl *FloatLiteral) TokenLiteral() string { return fl.Token.Literal }
+func (fl *FloatLiteral) Pos() token.Pos       { return fl.Token.Pos }
+func (fl *FloatLiteral) String() string       { return fl.Token.Literal }
+
+type StringLiteral struct {
+	Token token.Token
+	Value string
+}
+
+func (sl *StringLiteral) expressionNode()      {}
+func (sl *StringLiteral) TokenLiteral() string { return sl.Token.Literal }
+func (sl *StringLiteral) Pos() token.Pos       { return sl.Token.Pos }
+func (sl *StringLiteral) String() string       { return sl.Token.Literal }
+
+type ArrayLiteral struct {
+	Token    token.Token
+	Elements []Expression
+}
+
+func (al *ArrayLiteral) expressionNode()      {}
+func (al *ArrayLiteral) TokenLiteral() string { return al.Token.Literal }
+func (al *ArrayLiteral) Pos() token.Pos       { return al.Token.Pos }
+func (al *ArrayLiteral) String() string {
+	var out bytes.Buffer
+
+	elements := []string{}
+	for _, el := range al.Elements {
+		elements = append(elements, el.String())
+	}
+
+	out.WriteString("[")
+	out.WriteString(strings.Join(elements, ", "))
+	out.WriteString("]")
+
+	return out.String()
+}
+
+// StructLiteral constructs a struct instance, e.g. `Point{ x: 1, y: 2 }`.
+// Name resolves which declared struct it's building; the evaluator checks
+// Fields against that declaration's field list.
+type StructLiteral struct {
+	Token  token.Token // the '{' token
+	Name   *Identifier
+	Fields map[string]Expression
+}
+
+func (sl *StructLiteral) expressionNode()      {}
+func (sl *StructLiteral) TokenLiteral() string { return sl.Token.Literal }
+func (sl *StructLiteral) Pos() token.Pos       { return sl.Token.Pos }
+func (sl *StructLiteral) String() string {
+	var out bytes.Buffer
+
+	fields := []string{}
+	for name, value := range sl.Fields {
+		fields = append(fields, name+": "+value.String())
+	}
+
+	out.WriteString(sl.Name.String())
+	out.WriteString("{ ")
+	out.WriteString(strings.Join(fields, ", "))
+	out.WriteString(" }")
+
+	return out.String()
+}
+
+type HashLiteral struct {
+	Token token.Token
+	Pairs map[Expression]Expression
+}
+
+func (hl *HashLiteral) expressionNode()      {}
+func (hl *HashLiteral) TokenLiteral() string { return hl.Token.Literal }
+func (hl *HashLiteral) Pos() token.Pos       { return hl.Token.Pos }
+func (hl *HashLiteral) String() string {
+	var out bytes.Buffer
+
+	pairs := []string{}
+	for key, value := range hl.Pairs {
+		pairs = append(pairs, key.String()+":"+value.String())
+	}
+
+	out.WriteString("{")
+	out.WriteString(strings.Join(pairs, ", "))
+	out.WriteString("}")
+
+	return out.String()
+}
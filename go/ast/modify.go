@@ -0,0 +1,105 @@
+package ast
+
+// ModifierFunc is applied to every node Modify visits, post-order (children
+// first), and returns the node that should take its place.
+type ModifierFunc func(Node) Node
+
+// Modify walks node's children, replacing each with the result of modifier,
+// then applies modifier to node itself. It mutates composite nodes in
+// place. It's the generic traversal ExpandMacros uses to find and replace
+// CallExpressions throughout a program.
+func Modify(node Node, modifier ModifierFunc) Node {
+	switch node := node.(type) {
+	case *Program:
+		for i, stmt := range node.Statements {
+			node.Statements[i], _ = Modify(stmt, modifier).(Statement)
+		}
+
+	case *ExpressionStatement:
+		node.Expression, _ = Modify(node.Expression, modifier).(Expression)
+
+	case *BlockStatement:
+		for i, stmt := range node.Statements {
+			node.Statements[i], _ = Modify(stmt, modifier).(Statement)
+		}
+
+	case *ReturnStatement:
+		if node.ReturnValue != nil {
+			node.ReturnValue, _ = Modify(node.ReturnValue, modifier).(Expression)
+		}
+
+	case *DeclarationStatement:
+		if node.Value != nil {
+			node.Value, _ = Modify(node.Value, modifier).(Expression)
+		}
+
+	case *AssignStatement:
+		if node.Value != nil {
+			node.Value, _ = Modify(node.Value, modifier).(Expression)
+		}
+
+	case *WhileStatement:
+		node.Condition, _ = Modify(node.Condition, modifier).(Expression)
+		node.Body, _ = Modify(node.Body, modifier).(*BlockStatement)
+
+	case *ForStatement:
+		if node.Init != nil {
+			node.Init, _ = Modify(node.Init, modifier).(Statement)
+		}
+		if node.Condition != nil {
+			node.Condition, _ = Modify(node.Condition, modifier).(Expression)
+		}
+		if node.Post != nil {
+			node.Post, _ = Modify(node.Post, modifier).(Statement)
+		}
+		node.Body, _ = Modify(node.Body, modifier).(*BlockStatement)
+
+	case *UnaryExpression:
+		node.Right, _ = Modify(node.Right, modifier).(Expression)
+
+	case *BinaryExpression:
+		node.Left, _ = Modify(node.Left, modifier).(Expression)
+		node.Right, _ = Modify(node.Right, modifier).(Expression)
+
+	case *IndexExpression:
+		node.Left, _ = Modify(node.Left, modifier).(Expression)
+		node.Index, _ = Modify(node.Index, modifier).(Expression)
+
+	case *FieldAccessExpression:
+		node.Left, _ = Modify(node.Left, modifier).(Expression)
+
+	case *IfExpression:
+		node.Condition, _ = Modify(node.Condition, modifier).(Expression)
+		node.Consequence, _ = Modify(node.Consequence, modifier).(*BlockStatement)
+		if node.Alternative != nil {
+			node.Alternative, _ = Modify(node.Alternative, modifier).(*BlockStatement)
+		}
+
+	case *ArrayLiteral:
+		for i, el := range node.Elements {
+			node.Elements[i], _ = Modify(el, modifier).(Expression)
+		}
+
+	case *HashLiteral:
+		newPairs := make(map[Expression]Expression, len(node.Pairs))
+		for key, val := range node.Pairs {
+			newKey, _ := Modify(key, modifier).(Expression)
+			newVal, _ := Modify(val, modifier).(Expression)
+			newPairs[newKey] = newVal
+		}
+		node.Pairs = newPairs
+
+	case *StructLiteral:
+		for name, val := range node.Fields {
+			node.Fields[name], _ = Modify(val, modifier).(Expression)
+		}
+
+	case *CallExpression:
+		node.Function, _ = Modify(node.Function, modifier).(Expression)
+		for i, arg := range node.Arguments {
+			node.Arguments[i], _ = Modify(arg, modifier).(Expression)
+		}
+	}
+
+	return modifier(node)
+}
@@ -33,13 +33,48 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 		}
 		env.Set(node.Name.Value, val)
 
+	case *ast.AssignStatement:
+		val := Eval(node.Value, env)
+		if isError(val) {
+			return val
+		}
+		if !env.Assign(node.Name.Value, val) {
+			return newError(node, "identifier not found: "+node.Name.Value)
+		}
+
 	case *ast.ExpressionStatement:
 		return Eval(node.Expression, env)
 
 	case *ast.BlockStatement:
 		return evalBlockStatement(node, env)
 
+	case *ast.WhileStatement:
+		return evalWhileStatement(node, env)
+
+	case *ast.ForStatement:
+		return evalForStatement(node, env)
+
+	case *ast.BreakStatement:
+		return &object.Break{Pos: node.Pos()}
+
+	case *ast.ContinueStatement:
+		return &object.Continue{Pos: node.Pos()}
+
+	case *ast.StructDeclaration:
+		return evalStructDeclaration(node, env)
+
 	// expressions
+
+	case *ast.FieldAccessExpression:
+		left := Eval(node.Left, env)
+		if isError(left) {
+			return left
+		}
+		return evalFieldAccessExpression(node, left)
+
+	case *ast.StructLiteral:
+		return evalStructLiteral(node, env)
+
 	case *ast.IndexExpression:
 		left := Eval(node.Left, env)
 		if isError(left) {
@@ -49,7 +84,7 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 		if isError(index) {
 			return index
 		}
-		return evalIndexExpression(left, index)
+		return evalIndexExpression(node, left, index)
 
 	case *ast.CallExpression:
 		fn := Eval(node.Function, env)
@@ -60,7 +95,7 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 		if len(args) == 1 && isError(args[0]) {
 			return args[0]
 		}
-		return applyFunction(fn, args)
+		return applyFunction(node, fn, args)
 
 	case *ast.IfExpression:
 		return evalIfExpression(node, env)
@@ -70,7 +105,7 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 		if isError(right) {
 			return right
 		}
-		return evalUnaryExpression(node.Operator, right)
+		return evalUnaryExpression(node, right)
 
 	case *ast.BinaryExpression:
 		left := Eval(node.Left, env)
@@ -81,7 +116,7 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 		if isError(right) {
 			return right
 		}
-		return evalBinaryExpression(node.Operator, left, right)
+		return evalBinaryExpression(node, left, right)
 
 	// literals
 	case *ast.FunctionLiteral:
@@ -98,6 +133,9 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 	case *ast.IntegerLiteral:
 		return &object.Integer{Value: node.Value}
 
+	case *ast.FloatLiteral:
+		return &object.Float{Value: node.Value}
+
 	case *ast.StringLiteral:
 		return &object.String{Value: node.Value}
 
@@ -111,6 +149,11 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 	case *ast.HashLiteral:
 		return evalHashLiteral(node, env)
 
+	case *ast.QuoteExpression:
+		return evalQuoteExpression(node, env)
+
+	default:
+		return newError(node, "unsupported node type: %T", node)
 	}
 
 	return nil
@@ -127,6 +170,10 @@ func evalProgram(program *ast.Program, env *object.Environment) object.Object {
 			return result.Value
 		case *object.Error:
 			return result
+		case *object.Break:
+			return newError(nil, "break outside of a loop")
+		case *object.Continue:
+			return newError(nil, "continue outside of a loop")
 		}
 	}
 
@@ -146,7 +193,8 @@ func evalBlockStatement(
 
 		if result != nil {
 			rt := result.Type()
-			if rt == object.RETURN_VALUE || rt == object.ERROR {
+			if rt == object.RETURN_VALUE || rt == object.ERROR ||
+				rt == object.BREAK || rt == object.CONTINUE {
 				return result
 			}
 		}
@@ -155,6 +203,92 @@ func evalBlockStatement(
 	return result
 }
 
+// evalWhileStatement evaluates the loop body while condition stays truthy.
+// A Break unwinds the loop and evaluates to NULL; a Continue just ends the
+// current iteration early and lets the condition be checked again.
+func evalWhileStatement(ws *ast.WhileStatement, env *object.Environment) object.Object {
+	for {
+		condition := Eval(ws.Condition, env)
+		if isError(condition) {
+			return condition
+		}
+		if !isTruthy(condition) {
+			break
+		}
+
+		result := Eval(ws.Body, env)
+		if result != nil {
+			switch result.Type() {
+			case object.ERROR:
+				return result
+			case object.RETURN_VALUE:
+				return result
+			case object.BREAK:
+				return NULL
+			}
+		}
+	}
+
+	return NULL
+}
+
+// evalForStatement evaluates the classic three-clause for loop. Unlike
+// evalIfExpression/evalBlockStatement, it reuses the same env for Init, the
+// condition, the body, and Post, the same no-block-scoping behavior the
+// rest of the evaluator already relies on.
+func evalForStatement(fs *ast.ForStatement, env *object.Environment) object.Object {
+	if fs.Init != nil {
+		result := Eval(fs.Init, env)
+		if isError(result) {
+			return result
+		}
+	}
+
+	for {
+		if fs.Condition != nil {
+			condition := Eval(fs.Condition, env)
+			if isError(condition) {
+				return condition
+			}
+			if !isTruthy(condition) {
+				break
+			}
+		}
+
+		result := Eval(fs.Body, env)
+		if result != nil {
+			switch result.Type() {
+			case object.ERROR:
+				return result
+			case object.RETURN_VALUE:
+				return result
+			case object.BREAK:
+				return NULL
+			}
+		}
+
+		if fs.Post != nil {
+			postResult := Eval(fs.Post, env)
+			if isError(postResult) {
+				return postResult
+			}
+		}
+	}
+
+	return NULL
+}
+
+func evalStructDeclaration(sd *ast.StructDeclaration, env *object.Environment) object.Object {
+	fields := make([]string, len(sd.Fields))
+	for i, f := range sd.Fields {
+		fields[i] = f.Value
+	}
+
+	env.Set(sd.Name.Value, &object.Struct{Name: sd.Name.Value, Fields: fields})
+
+	return nil
+}
+
 // expressions
 
 func evalExpressions(
@@ -174,14 +308,14 @@ func evalExpressions(
 	return result
 }
 
-func evalIndexExpression(left, index object.Object) object.Object {
+func evalIndexExpression(node ast.Node, left, index object.Object) object.Object {
 	switch {
 	case left.Type() == object.ARRAY && index.Type() == object.INTEGER:
 		return evalArrayIndexExpression(left, index)
 	case left.Type() == object.HASH:
-		return evalHashIndexExpression(left, index)
+		return evalHashIndexExpression(node, left, index)
 	default:
-		return newError("index operator not supported: %s", left.Type())
+		return newError(node, "index operator not supported: %s", left.Type())
 	}
 }
 
@@ -197,12 +331,12 @@ func evalArrayIndexExpression(array, index object.Object) object.Object {
 	return arrayObject.Elements[idx]
 }
 
-func evalHashIndexExpression(hash, index object.Object) object.Object {
+func evalHashIndexExpression(node ast.Node, hash, index object.Object) object.Object {
 	hashObject := hash.(*object.Hash)
 
 	key, ok := index.(object.Hashable)
 	if !ok {
-		return newError("unusable as hash key: %s", index.Type())
+		return newError(node, "unusable as hash key: %s", index.Type())
 	}
 
 	pair, ok := hashObject.Pairs[key.HashKey()]
@@ -231,27 +365,26 @@ func evalIfExpression(
 	}
 }
 
-func evalUnaryExpression(
-	operator token.TokenType,
-	right object.Object,
-) object.Object {
-	switch operator {
+func evalUnaryExpression(node *ast.UnaryExpression, right object.Object) object.Object {
+	switch node.Operator {
 	case token.MINUS:
-		return evalInverseExpression(right)
+		return evalInverseExpression(node, right)
 	case token.BANG:
 		return evalNegateExpression(right)
 	default:
-		return newError("unknown operation: %s%s", operator, right.Type())
+		return newError(node, "unknown operation: %s%s", node.Operator, right.Type())
 	}
 }
 
-func evalInverseExpression(right object.Object) object.Object {
-	if right.Type() != object.INTEGER {
-		return newError("unknown operation: -%s", right.Type())
+func evalInverseExpression(node ast.Node, right object.Object) object.Object {
+	switch right := right.(type) {
+	case *object.Integer:
+		return &object.Integer{Value: -right.Value}
+	case *object.Float:
+		return &object.Float{Value: -right.Value}
+	default:
+		return newError(node, "unknown operation: -%s", right.Type())
 	}
-
-	val := right.(*object.Integer).Value
-	return &object.Integer{Value: -val}
 }
 
 func evalNegateExpression(right object.Object) object.Object {
@@ -260,23 +393,26 @@ func evalNegateExpression(right object.Object) object.Object {
 		return FALSE
 	case FALSE:
 		return TRUE
-	case NULL:
-		return TRUE
 	default:
+		if right.Type() == object.NULL {
+			return TRUE
+		}
 		return FALSE
 	}
 }
 
-func evalBinaryExpression(
-	operator token.TokenType,
-	left, right object.Object,
-) object.Object {
+func evalBinaryExpression(node *ast.BinaryExpression, left, right object.Object) object.Object {
+	operator := node.Operator
+
 	switch {
 	case left.Type() == object.INTEGER && right.Type() == object.INTEGER:
-		return evalIntegerBinaryExpression(operator, left, right)
+		return evalIntegerBinaryExpression(node, left, right)
+
+	case left.Type() == object.FLOAT && right.Type() == object.FLOAT:
+		return evalFloatBinaryExpression(node, left, right)
 
 	case left.Type() == object.STRING && right.Type() == object.STRING:
-		return evalStringBinaryExpression(operator, left, right)
+		return evalStringBinaryExpression(node, left, right)
 
 	case operator == "==":
 		return boolToBooleanObject(left == right)
@@ -285,6 +421,7 @@ func evalBinaryExpression(
 
 	case left.Type() != right.Type():
 		return newError(
+			node,
 			"type mismatch: %s %s %s",
 			left.Type(),
 			operator,
@@ -292,6 +429,7 @@ func evalBinaryExpression(
 		)
 	default:
 		return newError(
+			node,
 			"unknown operation: %s %s %s",
 			left.Type(),
 			operator,
@@ -300,14 +438,11 @@ func evalBinaryExpression(
 	}
 }
 
-func evalIntegerBinaryExpression(
-	operator token.TokenType,
-	left, right object.Object,
-) object.Object {
+func evalIntegerBinaryExpression(node *ast.BinaryExpression, left, right object.Object) object.Object {
 	leftVal := left.(*object.Integer).Value
 	rightVal := right.(*object.Integer).Value
 
-	switch operator {
+	switch node.Operator {
 	case token.PLUS:
 		return &object.Integer{Value: leftVal + rightVal}
 	case token.MINUS:
@@ -328,23 +463,56 @@ func evalIntegerBinaryExpression(
 
 	default:
 		return newError(
+			node,
 			"unknown operation: %s %s %s",
 			left.Type(),
-			operator,
+			node.Operator,
 			right.Type(),
 		)
 	}
 }
 
-func evalStringBinaryExpression(
-	operator token.TokenType,
-	left, right object.Object,
-) object.Object {
-	if operator != token.PLUS {
+func evalFloatBinaryExpression(node *ast.BinaryExpression, left, right object.Object) object.Object {
+	leftVal := left.(*object.Float).Value
+	rightVal := right.(*object.Float).Value
+
+	switch node.Operator {
+	case token.PLUS:
+		return &object.Float{Value: leftVal + rightVal}
+	case token.MINUS:
+		return &object.Float{Value: leftVal - rightVal}
+	case token.ASTERISK:
+		return &object.Float{Value: leftVal * rightVal}
+	case token.SLASH:
+		return &object.Float{Value: leftVal / rightVal}
+
+	case token.LESS_THAN:
+		return boolToBooleanObject(leftVal < rightVal)
+	case token.GREATER_THAN:
+		return boolToBooleanObject(leftVal > rightVal)
+	case token.EQUALS:
+		return boolToBooleanObject(leftVal == rightVal)
+	case token.NOT_EQUALS:
+		return boolToBooleanObject(leftVal != rightVal)
+
+	default:
 		return newError(
+			node,
 			"unknown operation: %s %s %s",
 			left.Type(),
-			operator,
+			node.Operator,
+			right.Type(),
+		)
+	}
+}
+
+func evalStringBinaryExpression(node *ast.BinaryExpression, left, right object.Object) object.Object {
+	if node.Operator != token.PLUS {
+		return newError(
+			node,
+			"unknown operation: %s %s %s",
+			left.Type(),
+			node.Operator,
 			right.Type(),
 		)
 	}
@@ -365,11 +533,11 @@ func evalIdentifier(
 		return val
 	}
 
-	if builtin, ok := builtins[node.Value]; ok {
+	if builtin := object.GetBuiltinByName(node.Value); builtin != nil {
 		return builtin
 	}
 
-	return newError("identifier not found: " + node.Value)
+	return newError(node, "identifier not found: "+node.Value)
 }
 
 func evalHashLiteral(
@@ -386,7 +554,7 @@ func evalHashLiteral(
 
 		hashKey, ok := key.(object.Hashable)
 		if !ok {
-			return newError("unusable as hash key: %s", key.Type())
+			return newError(node, "unusable as hash key: %s", key.Type())
 		}
 
 		value := Eval(valueNode, env)
@@ -401,21 +569,161 @@ func evalHashLiteral(
 	return &object.Hash{Pairs: pairs}
 }
 
+// evalQuoteExpression evaluates any unquote(...) calls inside node.Node
+// against env, splicing their results back into the AST, then wraps the
+// rest of the node, unevaluated, in an *object.Quote.
+func evalQuoteExpression(node *ast.QuoteExpression, env *object.Environment) object.Object {
+	quoted := evalUnquoteCalls(node.Node, env)
+	return &object.Quote{Node: quoted}
+}
+
+func evalUnquoteCalls(quoted ast.Node, env *object.Environment) ast.Node {
+	return ast.Modify(quoted, func(node ast.Node) ast.Node {
+		if !isUnquoteCall(node) {
+			return node
+		}
+
+		call, ok := node.(*ast.CallExpression)
+		if !ok || len(call.Arguments) != 1 {
+			return node
+		}
+
+		unquoted := Eval(call.Arguments[0], env)
+		return convertObjectToASTNode(unquoted)
+	})
+}
+
+func isUnquoteCall(node ast.Node) bool {
+	call, ok := node.(*ast.CallExpression)
+	if !ok {
+		return false
+	}
+
+	ident, ok := call.Function.(*ast.Identifier)
+	if !ok {
+		return false
+	}
+
+	return ident.Value == "unquote"
+}
+
+// convertObjectToASTNode converts the result of evaluating an unquote(...)
+// call back into an AST node so it can be spliced into the quoted program.
+// A nested *object.Quote unwraps to the node it was already holding.
+func convertObjectToASTNode(obj object.Object) ast.Node {
+	switch obj := obj.(type) {
+	case *object.Integer:
+		return &ast.IntegerLiteral{
+			Token: token.Token{Type: token.INTEGER, Literal: fmt.Sprintf("%d", obj.Value)},
+			Value: obj.Value,
+		}
+
+	case *object.Float:
+		return &ast.FloatLiteral{
+			Token: token.Token{Type: token.FLOAT, Literal: fmt.Sprintf("%g", obj.Value)},
+			Value: obj.Value,
+		}
+
+	case *object.Boolean:
+		var tok token.Token
+		if obj.Value {
+			tok = token.Token{Type: token.TRUE, Literal: "true"}
+		} else {
+			tok = token.Token{Type: token.FALSE, Literal: "false"}
+		}
+		return &ast.BooleanLiteral{Token: tok, Value: obj.Value}
+
+	case *object.String:
+		return &ast.StringLiteral{
+			Token: token.Token{Type: token.STRING, Literal: obj.Value},
+			Value: obj.Value,
+		}
+
+	case *object.Quote:
+		return obj.Node
+
+	default:
+		panic(fmt.Sprintf("cannot unquote a %s", obj.Type()))
+	}
+}
+
+// evalStructLiteral looks up the struct declaration node.Name refers to and
+// builds an instance, requiring the literal's fields to exactly match the
+// declaration's: no missing fields, no extras, no typos.
+func evalStructLiteral(node *ast.StructLiteral, env *object.Environment) object.Object {
+	decl, ok := env.Get(node.Name.Value)
+	if !ok {
+		return newError(node, "struct not found: "+node.Name.Value)
+	}
+
+	structDef, ok := decl.(*object.Struct)
+	if !ok {
+		return newError(node, "not a struct: %s", node.Name.Value)
+	}
+
+	if len(node.Fields) != len(structDef.Fields) {
+		return newError(
+			node,
+			"wrong number of fields for %s: expected %d, got %d",
+			structDef.Name, len(structDef.Fields), len(node.Fields),
+		)
+	}
+
+	fields := make(map[string]object.Object, len(structDef.Fields))
+	for _, name := range structDef.Fields {
+		valueNode, ok := node.Fields[name]
+		if !ok {
+			return newError(node, "missing field %q for struct %s", name, structDef.Name)
+		}
+
+		value := Eval(valueNode, env)
+		if isError(value) {
+			return value
+		}
+		fields[name] = value
+	}
+
+	return &object.StructInstance{Struct: structDef, Fields: fields}
+}
+
+func evalFieldAccessExpression(node *ast.FieldAccessExpression, left object.Object) object.Object {
+	instance, ok := left.(*object.StructInstance)
+	if !ok {
+		return newError(node, "not a struct instance: %s", left.Type())
+	}
+
+	value, ok := instance.Fields[node.Field.Value]
+	if !ok {
+		return newError(node, "undefined field %q on struct %s", node.Field.Value, instance.Struct.Name)
+	}
+
+	return value
+}
+
 // function
 
-func applyFunction(function object.Object, args []object.Object) object.Object {
+func applyFunction(node *ast.CallExpression, function object.Object, args []object.Object) object.Object {
 	switch function := function.(type) {
 
 	case *object.Function:
 		extendedEnv := extendFunctionEnv(function, args)
 		evaluated := Eval(function.Body, extendedEnv)
+		switch evaluated := evaluated.(type) {
+		case *object.Error:
+			evaluated.Trace = append(evaluated.Trace, node.Pos())
+			return evaluated
+		case *object.Break:
+			return newError(node, "break outside of a loop")
+		case *object.Continue:
+			return newError(node, "continue outside of a loop")
+		}
 		return unwrapReturnValue(evaluated)
 
 	case *object.Builtin:
 		return function.Fn(args...)
 
 	default:
-		return newError("not a function: %s", function.Type())
+		return newError(node, "not a function: %s", function.Type())
 	}
 }
 
@@ -451,14 +759,12 @@ func boolToBooleanObject(input bool) *object.Boolean {
 
 func isTruthy(obj object.Object) bool {
 	switch obj {
-	case NULL:
-		return false
 	case TRUE:
 		return true
 	case FALSE:
 		return false
 	default:
-		return true
+		return obj.Type() != object.NULL
 	}
 }
 
@@ -470,6 +776,14 @@ func isError(obj object.Object) bool {
 	return false
 }
 
-func newError(format string, a ...interface{}) *object.Error {
-	return &object.Error{Message: fmt.Sprintf(format, a...)}
-}
\ No newline at end of file
+// newError builds an *object.Error carrying node's source position, so the
+// top-level error printer can point straight at the offending code. node may
+// be nil (e.g. from a builtin, which has no single offending AST node), in
+// which case the error is reported without a position.
+func newError(node ast.Node, format string, a ...interface{}) *object.Error {
+	err := &object.Error{Message: fmt.Sprintf(format, a...)}
+	if node != nil {
+		err.Pos = node.Pos()
+	}
+	return err
+}
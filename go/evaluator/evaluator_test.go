@@ -0,0 +1,226 @@
+package evaluator
+
+import (
+	"gorb/lexer"
+	"gorb/object"
+	"gorb/parser"
+	"testing"
+)
+
+func testEval(input string) object.Object {
+	l := lexer.New(input)
+	p := parser.New(l)
+	program := p.ParseProgram()
+	env := object.NewEnvironment()
+
+	return Eval(program, env)
+}
+
+func testIntegerObject(t *testing.T, obj object.Object, expected int64) bool {
+	result, ok := obj.(*object.Integer)
+	if !ok {
+		t.Errorf("object is not Integer. got=%T (%+v)", obj, obj)
+		return false
+	}
+	if result.Value != expected {
+		t.Errorf("object has wrong value. got=%d, want=%d", result.Value, expected)
+		return false
+	}
+
+	return true
+}
+
+func TestWhileStatement(t *testing.T) {
+	input := `
+	let i = 0;
+	let sum = 0;
+	while (i < 5) {
+		let sum = sum + i;
+		let i = i + 1;
+	}
+	sum;
+	`
+
+	evaluated := testEval(input)
+	testIntegerObject(t, evaluated, 10)
+}
+
+func TestWhileStatementBreak(t *testing.T) {
+	input := `
+	let i = 0;
+	while (true) {
+		if (i == 3) { break; }
+		let i = i + 1;
+	}
+	i;
+	`
+
+	evaluated := testEval(input)
+	testIntegerObject(t, evaluated, 3)
+}
+
+func TestForStatement(t *testing.T) {
+	input := `
+	let sum = 0;
+	for (let i = 0; i < 5; let i = i + 1) {
+		let sum = sum + i;
+	}
+	sum;
+	`
+
+	evaluated := testEval(input)
+	testIntegerObject(t, evaluated, 10)
+}
+
+func TestForStatementContinue(t *testing.T) {
+	input := `
+	let sum = 0;
+	for (let i = 0; i < 5; let i = i + 1) {
+		if (i == 2) { continue; }
+		let sum = sum + i;
+	}
+	sum;
+	`
+
+	evaluated := testEval(input)
+	testIntegerObject(t, evaluated, 8)
+}
+
+func TestAssignStatementUpdatesExistingBinding(t *testing.T) {
+	input := `
+	let x = 1;
+	x = 2;
+	x;
+	`
+
+	evaluated := testEval(input)
+	testIntegerObject(t, evaluated, 2)
+}
+
+func TestAssignStatementUpdatesEnclosingScope(t *testing.T) {
+	input := `
+	let sum = 0;
+	while (sum < 5) {
+		sum = sum + 1;
+	}
+	sum;
+	`
+
+	evaluated := testEval(input)
+	testIntegerObject(t, evaluated, 5)
+}
+
+func TestAssignStatementToUnboundNameIsError(t *testing.T) {
+	input := `x = 1;`
+
+	evaluated := testEval(input)
+	errObj, ok := evaluated.(*object.Error)
+	if !ok {
+		t.Fatalf("expected an Error object, got=%T (%+v)", evaluated, evaluated)
+	}
+	if errObj.Message != "identifier not found: x" {
+		t.Fatalf("unexpected error message: %q", errObj.Message)
+	}
+}
+
+func TestStructLiteralAndFieldAccess(t *testing.T) {
+	input := `
+	struct Point { x: int, y: int }
+	let p = Point{ x: 3, y: 4 };
+	p.x + p.y;
+	`
+
+	evaluated := testEval(input)
+	testIntegerObject(t, evaluated, 7)
+}
+
+func TestStructLiteralWrongFieldCountIsError(t *testing.T) {
+	input := `
+	struct Point { x: int, y: int }
+	Point{ x: 1 };
+	`
+
+	evaluated := testEval(input)
+	err, ok := evaluated.(*object.Error)
+	if !ok {
+		t.Fatalf("no error object returned. got=%T (%+v)", evaluated, evaluated)
+	}
+	if err.Message != "wrong number of fields for Point: expected 2, got 1" {
+		t.Errorf("wrong error message. got=%q", err.Message)
+	}
+}
+
+func TestFieldAccessOnUnknownFieldIsError(t *testing.T) {
+	input := `
+	struct Point { x: int, y: int }
+	let p = Point{ x: 1, y: 2 };
+	p.z;
+	`
+
+	evaluated := testEval(input)
+	err, ok := evaluated.(*object.Error)
+	if !ok {
+		t.Fatalf("no error object returned. got=%T (%+v)", evaluated, evaluated)
+	}
+	if err.Message != `undefined field "z" on struct Point` {
+		t.Errorf("wrong error message. got=%q", err.Message)
+	}
+}
+
+func TestBreakOutsideLoopIsError(t *testing.T) {
+	evaluated := testEval("break;")
+
+	err, ok := evaluated.(*object.Error)
+	if !ok {
+		t.Fatalf("no error object returned. got=%T (%+v)", evaluated, evaluated)
+	}
+	if err.Message != "break outside of a loop" {
+		t.Errorf("wrong error message. got=%q", err.Message)
+	}
+}
+
+func TestContinueOutsideLoopIsError(t *testing.T) {
+	evaluated := testEval("continue;")
+
+	err, ok := evaluated.(*object.Error)
+	if !ok {
+		t.Fatalf("no error object returned. got=%T (%+v)", evaluated, evaluated)
+	}
+	if err.Message != "continue outside of a loop" {
+		t.Errorf("wrong error message. got=%q", err.Message)
+	}
+}
+
+func TestQuoteUnquote(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`quote(5)`, `5`},
+		{`quote(5 + 8)`, `(5 + 8)`},
+		{`quote(foobar)`, `foobar`},
+		{`quote(foobar + barfoo)`, `(foobar + barfoo)`},
+		{`quote(unquote(4 + 4))`, `8`},
+		{`quote(8 + unquote(4 + 4))`, `(8 + 8)`},
+		{`quote(unquote(4 + 4) + 8)`, `(8 + 8)`},
+		{`let quotedInfix = quote(4 + 4); quote(unquote(quotedInfix))`, `(4 + 4)`},
+		{`quote(unquote(true))`, `true`},
+		{`quote(unquote(true == false))`, `false`},
+		{`quote(unquote("hello" + " world"))`, `hello world`},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		quote, ok := evaluated.(*object.Quote)
+		if !ok {
+			t.Fatalf("expected *object.Quote. got=%T (%+v) for %q", evaluated, evaluated, tt.input)
+		}
+		if quote.Node == nil {
+			t.Fatalf("quote.Node is nil for %q", tt.input)
+		}
+		if quote.Node.String() != tt.expected {
+			t.Errorf("not equal for %q. want=%q, got=%q", tt.input, tt.expected, quote.Node.String())
+		}
+	}
+}
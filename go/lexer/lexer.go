@@ -1,12 +1,21 @@
 package lexer
 
 import (
+	"fmt"
 	"gorb/token"
 	"strings"
 )
 
 func New(input string) *Lexer {
-	l := &Lexer{input: input, pos: token.Pos{Ln: 1, Col: 0}}
+	return NewWithHandler(input, nil)
+}
+
+// NewWithHandler is New with an ErrorHandler that's called for every
+// ILLEGAL token the lexer produces, so a caller can report bad characters
+// with real position data instead of just seeing an ILLEGAL token show up
+// later in the token stream.
+func NewWithHandler(input string, h token.ErrorHandler) *Lexer {
+	l := &Lexer{input: input, pos: token.Pos{Ln: 1, Col: 0}, handler: h}
 	l.readChar()
 	return l
 }
@@ -17,6 +26,7 @@ type Lexer struct {
 	readPosition int
 	ch           byte
 	pos          token.Pos
+	handler      token.ErrorHandler
 }
 
 func (l *Lexer) readChar() {
@@ -61,6 +71,19 @@ func (l *Lexer) NextToken() token.Token {
 	case '*':
 		tok = l.newToken(token.ASTERISK)
 	case '/':
+		if l.peekChar() == '/' {
+			tok.Pos = l.pos
+			tok.Literal = l.readLineComment()
+			tok.Type = token.COMMENT
+			tok.Len = len(tok.Literal)
+			return tok
+		} else if l.peekChar() == '*' {
+			tok.Pos = l.pos
+			tok.Literal = l.readBlockComment()
+			tok.Type = token.COMMENT
+			tok.Len = len(tok.Literal)
+			return tok
+		}
 		tok = l.newToken(token.SLASH)
 	case '<':
 		tok = l.newToken(token.LESS_THAN)
@@ -82,6 +105,8 @@ func (l *Lexer) NextToken() token.Token {
 		tok = l.newToken(token.BRACKET_CLOSE)
 	case ':':
 		tok = l.newToken(token.COLON)
+	case '.':
+		tok = l.newToken(token.DOT)
 	case ';':
 		tok = l.newToken(token.SEMICOLON)
 	case '"':
@@ -89,9 +114,6 @@ func (l *Lexer) NextToken() token.Token {
 		tok.Literal = l.readString()
 		tok.Type = token.STRING
 		tok.Len = len(tok.Literal)
-	case '\n':
-		l.pos.Ln++
-		l.pos.Col = 0
 	case 0:
 		tok = l.newToken(token.EOF)
 	default:
@@ -103,12 +125,20 @@ func (l *Lexer) NextToken() token.Token {
 			return tok
 		} else if isDigit(l.ch) {
 			tok.Pos = l.pos
-			tok.Type = token.INTEGER
-			tok.Literal = l.readNumber()
+			literal, isFloat := l.readNumber()
+			tok.Literal = literal
+			if isFloat {
+				tok.Type = token.FLOAT
+			} else {
+				tok.Type = token.INTEGER
+			}
 			tok.Len = len(tok.Literal)
 			return tok
 		} else {
 			tok = l.newToken(token.ILLEGAL)
+			if l.handler != nil {
+				l.handler.Error(tok.Pos, fmt.Sprintf("illegal character %q", l.ch))
+			}
 		}
 	}
 
@@ -122,6 +152,10 @@ func (l *Lexer) newToken(tt token.TokenType) token.Token {
 
 func (l *Lexer) skipWhitespace() {
 	for l.ch == ' ' || l.ch == '\t' || l.ch == '\n' || l.ch == '\r' {
+		if l.ch == '\n' {
+			l.pos.Ln++
+			l.pos.Col = 0
+		}
 		l.readChar()
 	}
 }
@@ -153,11 +187,85 @@ func isDigit(ch byte) bool {
 	return '0' <= ch && ch <= '9'
 }
 
-func (l *Lexer) readNumber() string {
+// readNumber reads an integer or float literal starting at l.ch, which must
+// be a digit. A `.` is only consumed as a decimal point when it's followed
+// by another digit, so `arr[0].x`-style field access still lexes its `.` as
+// token.DOT instead of being swallowed into the number. An `e`/`E` exponent
+// is recognized with an optional sign, e.g. `1e10` or `2.5e-2`.
+func (l *Lexer) readNumber() (string, bool) {
 	position := l.position
+	isFloat := false
+
 	for isDigit(l.ch) {
 		l.readChar()
 	}
+
+	if l.ch == '.' && isDigit(l.peekChar()) {
+		isFloat = true
+		l.readChar()
+		for isDigit(l.ch) {
+			l.readChar()
+		}
+	}
+
+	if l.ch == 'e' || l.ch == 'E' {
+		next := l.peekChar()
+		hasExponent := isDigit(next)
+		if !hasExponent && (next == '+' || next == '-') && l.readPosition+1 < len(l.input) {
+			hasExponent = isDigit(l.input[l.readPosition+1])
+		}
+
+		if hasExponent {
+			isFloat = true
+			l.readChar()
+			if l.ch == '+' || l.ch == '-' {
+				l.readChar()
+			}
+			for isDigit(l.ch) {
+				l.readChar()
+			}
+		}
+	}
+
+	return l.input[position:l.position], isFloat
+}
+
+// readLineComment reads a `//` comment from the leading slash up to (but not
+// including) the newline that ends it, or EOF, leaving l.ch there so the
+// caller's ordinary newline handling still runs on the next NextToken call.
+func (l *Lexer) readLineComment() string {
+	position := l.position
+	for l.ch != '\n' && l.ch != 0 {
+		l.readChar()
+	}
+	return l.input[position:l.position]
+}
+
+// readBlockComment reads a `/* ... */` comment from the leading slash
+// through the closing `*/`, or to EOF if it's never closed. It tracks
+// newlines itself, since it bypasses skipWhitespace while inside the
+// comment.
+func (l *Lexer) readBlockComment() string {
+	position := l.position
+	l.readChar()
+	l.readChar()
+
+	for {
+		if l.ch == 0 {
+			break
+		}
+		if l.ch == '*' && l.peekChar() == '/' {
+			l.readChar()
+			l.readChar()
+			break
+		}
+		if l.ch == '\n' {
+			l.pos.Ln++
+			l.pos.Col = 0
+		}
+		l.readChar()
+	}
+
 	return l.input[position:l.position]
 }
 
@@ -191,4 +299,4 @@ func (l *Lexer) readString() string {
 		b.WriteByte(l.ch)
 	}
 	return b.String()
-}
\ No newline at end of file
+}
@@ -15,7 +15,7 @@ func TestNextToken(t *testing.T) {
 	};
 	
 	let result = add(five, ten);
-	!-/*5;
+	!- / * 5;
 	5 < 10 > 5;
 	
 	if (5<10) {
@@ -145,4 +145,174 @@ func TestNextToken(t *testing.T) {
 			)
 		}
 	}
-}
\ No newline at end of file
+}
+
+func TestLineComment(t *testing.T) {
+	input := "let a = 1; // a comment\nlet b = 2;"
+
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{token.DECLARATION, "let"},
+		{token.IDENTIFIER, "a"},
+		{token.ASSIGN, "="},
+		{token.INTEGER, "1"},
+		{token.SEMICOLON, ";"},
+		{token.COMMENT, "// a comment"},
+		{token.DECLARATION, "let"},
+		{token.IDENTIFIER, "b"},
+		{token.ASSIGN, "="},
+		{token.INTEGER, "2"},
+		{token.SEMICOLON, ";"},
+	}
+
+	l := New(input)
+
+	for i, tt := range tests {
+		tok := l.NextToken()
+		if tok.Type != tt.expectedType || tok.Literal != tt.expectedLiteral {
+			t.Fatalf(
+				"tests[%d] - wrong token, expected=%q %q, got=%q %q",
+				i, tt.expectedType, tt.expectedLiteral, tok.Type, tok.Literal,
+			)
+		}
+	}
+}
+
+func TestBlockComment(t *testing.T) {
+	input := "let a = /* spans\nlines */ 1;"
+
+	l := New(input)
+
+	tok := l.NextToken()
+	if tok.Type != token.DECLARATION {
+		t.Fatalf("expected DECLARATION, got %q", tok.Type)
+	}
+	tok = l.NextToken()
+	if tok.Type != token.IDENTIFIER {
+		t.Fatalf("expected IDENTIFIER, got %q", tok.Type)
+	}
+	tok = l.NextToken()
+	if tok.Type != token.ASSIGN {
+		t.Fatalf("expected ASSIGN, got %q", tok.Type)
+	}
+
+	tok = l.NextToken()
+	if tok.Type != token.COMMENT {
+		t.Fatalf("expected COMMENT, got %q", tok.Type)
+	}
+	if tok.Literal != "/* spans\nlines */" {
+		t.Fatalf("unexpected comment literal: %q", tok.Literal)
+	}
+
+	tok = l.NextToken()
+	if tok.Type != token.INTEGER || tok.Pos.Ln != 2 {
+		t.Fatalf("expected INTEGER on line 2 after the block comment, got %q at %v", tok.Type, tok.Pos)
+	}
+}
+
+func TestUnterminatedBlockCommentReadsToEOF(t *testing.T) {
+	input := "/* never closed"
+
+	l := New(input)
+	tok := l.NextToken()
+	if tok.Type != token.COMMENT || tok.Literal != input {
+		t.Fatalf("expected the whole input as one COMMENT, got %q %q", tok.Type, tok.Literal)
+	}
+
+	if tok = l.NextToken(); tok.Type != token.EOF {
+		t.Fatalf("expected EOF after the unterminated comment, got %q", tok.Type)
+	}
+}
+
+func TestFloatLiterals(t *testing.T) {
+	tests := []struct {
+		input           string
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{"3.14", token.FLOAT, "3.14"},
+		{"0.5", token.FLOAT, "0.5"},
+		{"1e10", token.FLOAT, "1e10"},
+		{"2.5e-2", token.FLOAT, "2.5e-2"},
+	}
+
+	for i, tt := range tests {
+		l := New(tt.input)
+		tok := l.NextToken()
+		if tok.Type != tt.expectedType || tok.Literal != tt.expectedLiteral {
+			t.Fatalf(
+				"tests[%d] - wrong token for %q, expected=%q %q, got=%q %q",
+				i, tt.input, tt.expectedType, tt.expectedLiteral, tok.Type, tok.Literal,
+			)
+		}
+	}
+}
+
+func TestLoneDotIsNotSwallowedIntoAFloat(t *testing.T) {
+	l := New(".")
+	tok := l.NextToken()
+	if tok.Type != token.DOT {
+		t.Fatalf("expected a lone '.' to lex as DOT, got %q %q", tok.Type, tok.Literal)
+	}
+}
+
+func TestIndexThenFieldAccessDotIsNotPartOfTheNumber(t *testing.T) {
+	l := New("arr[0].x")
+
+	tests := []token.TokenType{
+		token.IDENTIFIER, token.BRACKET_OPEN, token.INTEGER, token.BRACKET_CLOSE, token.DOT, token.IDENTIFIER,
+	}
+
+	for i, want := range tests {
+		tok := l.NextToken()
+		if tok.Type != want {
+			t.Fatalf("tests[%d] - expected %q, got %q", i, want, tok.Type)
+		}
+	}
+}
+
+func TestNextTokenPos(t *testing.T) {
+	input := "let a = 1;\nlet b = 2;\n"
+
+	tests := []struct {
+		expectedType string
+		expectedLn   int
+	}{
+		{token.DECLARATION, 1},
+		{token.IDENTIFIER, 1},
+		{token.ASSIGN, 1},
+		{token.INTEGER, 1},
+		{token.SEMICOLON, 1},
+		{token.DECLARATION, 2},
+		{token.IDENTIFIER, 2},
+		{token.ASSIGN, 2},
+		{token.INTEGER, 2},
+		{token.SEMICOLON, 2},
+	}
+
+	l := New(input)
+
+	for i, tt := range tests {
+		tok := l.NextToken()
+
+		if string(tok.Type) != tt.expectedType {
+			t.Fatalf(
+				"tests[%d] - TokenType wrong, expected=%q, got %q",
+				i,
+				tt.expectedType,
+				tok.Type,
+			)
+		}
+
+		if tok.Pos.Ln != tt.expectedLn {
+			t.Fatalf(
+				"tests[%d] - Pos.Ln wrong, expected=%d, got %d",
+				i,
+				tt.expectedLn,
+				tok.Pos.Ln,
+			)
+		}
+	}
+}
@@ -12,6 +12,8 @@ var (
 	interactive bool
 	version     bool
 	debug       bool
+	noTypeCheck bool
+	useVM       bool
 )
 
 func init() {
@@ -28,17 +30,24 @@ func init() {
 	flag.BoolVar(&debug, "d", false, "enable debug mode")
 	flag.BoolVar(&interactive, "i", false, "enable interactive mode")
 	flag.BoolVar(&version, "v", false, "display version information")
+	flag.BoolVar(&noTypeCheck, "no-typecheck", false, "skip static type checking of the executed file")
+	flag.BoolVar(&useVM, "vm", false, "execute the file on the bytecode vm instead of the tree-walking evaluator")
 }
 
 func main() {
 	flag.Parse()
-	
+
 	if flag.NArg() == 1 {
-		env := run.ExecuteFile(os.Stdout, flag.Arg(0))
+		if useVM {
+			run.ExecuteFileVM(os.Stdout, flag.Arg(0), !noTypeCheck)
+			return
+		}
+
+		env := run.ExecuteFile(os.Stdout, flag.Arg(0), !noTypeCheck)
 		if env != nil && interactive {
 			run.StartRepl(os.Stdin, os.Stdout, env)
 		}
 	} else {
 		run.StartRepl(os.Stdin, os.Stdout, nil)
 	}
-}
\ No newline at end of file
+}
@@ -0,0 +1,133 @@
+package object
+
+import "fmt"
+
+// Builtins is the ordered registry of built-in functions. Order matters: the
+// compiler emits OpGetBuiltin with an index into this slice, so entries must
+// never be reordered or removed once shipped, only appended to.
+var Builtins = []struct {
+	Name    string
+	Builtin *Builtin
+}{
+	{
+		"len",
+		&Builtin{Fn: func(args ...Object) Object {
+			if len(args) != 1 {
+				return newBuiltinError("wrong number of arguments: got=%d, want=1", len(args))
+			}
+
+			switch arg := args[0].(type) {
+			case *String:
+				return &Integer{Value: int64(len(arg.Value))}
+			case *Array:
+				return &Integer{Value: int64(len(arg.Elements))}
+			default:
+				return newBuiltinError("argument to `len` not supported: got %s", arg.Type())
+			}
+		}},
+	},
+	{
+		"first",
+		&Builtin{Fn: func(args ...Object) Object {
+			if len(args) != 1 {
+				return newBuiltinError("wrong number of arguments: got=%d, want=1", len(args))
+			}
+
+			arr, ok := args[0].(*Array)
+			if !ok {
+				return newBuiltinError("argument to `first` must be ARRAY, got %s", args[0].Type())
+			}
+
+			if len(arr.Elements) > 0 {
+				return arr.Elements[0]
+			}
+
+			return &Null{}
+		}},
+	},
+	{
+		"last",
+		&Builtin{Fn: func(args ...Object) Object {
+			if len(args) != 1 {
+				return newBuiltinError("wrong number of arguments: got=%d, want=1", len(args))
+			}
+
+			arr, ok := args[0].(*Array)
+			if !ok {
+				return newBuiltinError("argument to `last` must be ARRAY, got %s", args[0].Type())
+			}
+
+			length := len(arr.Elements)
+			if length > 0 {
+				return arr.Elements[length-1]
+			}
+
+			return &Null{}
+		}},
+	},
+	{
+		"rest",
+		&Builtin{Fn: func(args ...Object) Object {
+			if len(args) != 1 {
+				return newBuiltinError("wrong number of arguments: got=%d, want=1", len(args))
+			}
+
+			arr, ok := args[0].(*Array)
+			if !ok {
+				return newBuiltinError("argument to `rest` must be ARRAY, got %s", args[0].Type())
+			}
+
+			length := len(arr.Elements)
+			if length > 0 {
+				newElements := make([]Object, length-1)
+				copy(newElements, arr.Elements[1:length])
+				return &Array{Elements: newElements}
+			}
+
+			return &Null{}
+		}},
+	},
+	{
+		"push",
+		&Builtin{Fn: func(args ...Object) Object {
+			if len(args) != 2 {
+				return newBuiltinError("wrong number of arguments: got=%d, want=2", len(args))
+			}
+
+			arr, ok := args[0].(*Array)
+			if !ok {
+				return newBuiltinError("argument to `push` must be ARRAY, got %s", args[0].Type())
+			}
+
+			length := len(arr.Elements)
+			newElements := make([]Object, length+1)
+			copy(newElements, arr.Elements)
+			newElements[length] = args[1]
+
+			return &Array{Elements: newElements}
+		}},
+	},
+	{
+		"puts",
+		&Builtin{Fn: func(args ...Object) Object {
+			for _, arg := range args {
+				fmt.Println(arg.Inspect())
+			}
+
+			return &Null{}
+		}},
+	},
+}
+
+func GetBuiltinByName(name string) *Builtin {
+	for _, b := range Builtins {
+		if b.Name == name {
+			return b.Builtin
+		}
+	}
+	return nil
+}
+
+func newBuiltinError(format string, a ...interface{}) *Error {
+	return &Error{Message: fmt.Sprintf(format, a...)}
+}
@@ -0,0 +1,32 @@
+package object
+
+import (
+	"fmt"
+	"gorb/code"
+)
+
+// CompiledFunction is a function literal after compilation: a flat slice of
+// bytecode plus the stack-frame shape (how many locals it needs, how many of
+// those are parameters) the vm sets up on call.
+type CompiledFunction struct {
+	Instructions  code.Instructions
+	NumLocals     int
+	NumParameters int
+}
+
+func (cf *CompiledFunction) Type() ObjectType { return COMPILED_FUNCTION }
+func (cf *CompiledFunction) Inspect() string {
+	return fmt.Sprintf("compiled function[%p]", cf)
+}
+
+// Closure pairs a CompiledFunction with the free variables it captured from
+// its defining scope, resolved once at OpClosure time.
+type Closure struct {
+	Fn   *CompiledFunction
+	Free []Object
+}
+
+func (c *Closure) Type() ObjectType { return CLOSURE }
+func (c *Closure) Inspect() string {
+	return fmt.Sprintf("closure[%p]", c)
+}
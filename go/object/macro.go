@@ -0,0 +1,46 @@
+package object
+
+import (
+	"bytes"
+	"gorb/ast"
+	"strings"
+)
+
+// Macro is a `macro(...) { ... }` binding, extracted from the program by
+// parser.DefineMacros before evaluation ever sees it. It's shaped like
+// Function, but its Body is expanded against unevaluated AST arguments
+// rather than called with runtime values.
+type Macro struct {
+	Parameters []*ast.Identifier
+	Body       *ast.BlockStatement
+	Env        *Environment
+}
+
+func (m *Macro) Type() ObjectType { return MACRO }
+func (m *Macro) Inspect() string {
+	var out bytes.Buffer
+
+	params := []string{}
+	for _, p := range m.Parameters {
+		params = append(params, p.String())
+	}
+
+	out.WriteString("macro")
+	out.WriteString("(")
+	out.WriteString(strings.Join(params, ", "))
+	out.WriteString(") {\n")
+	out.WriteString(m.Body.String())
+	out.WriteString("}")
+
+	return out.String()
+}
+
+// Quote wraps an AST node that a quote(...) expression captured without
+// evaluating it, so it can be spliced back into the caller's AST once macro
+// expansion finishes resolving any unquote(...) calls inside it.
+type Quote struct {
+	Node ast.Node
+}
+
+func (q *Quote) Type() ObjectType { return QUOTE }
+func (q *Quote) Inspect() string  { return "quote(" + q.Node.String() + ")" }
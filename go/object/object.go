@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"fmt"
 	"gorb/ast"
+	"gorb/token"
 	"hash/fnv"
 	"strings"
 )
@@ -20,16 +21,25 @@ type Hashable interface {
 }
 
 const (
-	FUNCTION     = "FUNCTION"
-	BUILTIN      = "BUILTIN"
-	NULL         = "NULL"
-	BOOLEAN      = "BOOLEAN"
-	INTEGER      = "INTEGER"
-	STRING       = "STRING"
-	ARRAY        = "ARRAY"
-	HASH         = "HASH"
-	RETURN_VALUE = "RETURN_VALUE"
-	ERROR        = "ERROR"
+	FUNCTION          = "FUNCTION"
+	BUILTIN           = "BUILTIN"
+	NULL              = "NULL"
+	BOOLEAN           = "BOOLEAN"
+	INTEGER           = "INTEGER"
+	FLOAT             = "FLOAT"
+	STRING            = "STRING"
+	ARRAY             = "ARRAY"
+	HASH              = "HASH"
+	RETURN_VALUE      = "RETURN_VALUE"
+	ERROR             = "ERROR"
+	COMPILED_FUNCTION = "COMPILED_FUNCTION"
+	CLOSURE           = "CLOSURE"
+	BREAK             = "BREAK"
+	CONTINUE          = "CONTINUE"
+	STRUCT            = "STRUCT"
+	STRUCT_INSTANCE   = "STRUCT_INSTANCE"
+	MACRO             = "MACRO"
+	QUOTE             = "QUOTE"
 )
 
 type Function struct {
@@ -104,6 +114,13 @@ func (i *Integer) HashKey() HashKey {
 	return HashKey{Type: i.Type(), Value: uint64(i.Value)}
 }
 
+type Float struct {
+	Value float64
+}
+
+func (f *Float) Type() ObjectType { return FLOAT }
+func (f *Float) Inspect() string  { return fmt.Sprintf("%g", f.Value) }
+
 type String struct {
 	Value string
 }
@@ -174,7 +191,27 @@ func (rv *ReturnValue) Inspect() string  { return rv.Value.Inspect() }
 
 type Error struct {
 	Message string
+	Pos     token.Pos
+	Trace   []token.Pos // call-site positions, innermost last
 }
 
 func (e *Error) Type() ObjectType { return ERROR }
-func (e *Error) Inspect() string  { return e.Message }
\ No newline at end of file
+func (e *Error) Inspect() string  { return e.Message }
+
+// Break and Continue are sentinel objects, the same way ReturnValue is:
+// evalWhileStatement/evalForStatement catch them to stop or skip an
+// iteration, and evalBlockStatement bubbles them up unevaluated so they
+// can escape nested blocks to reach the enclosing loop.
+type Break struct {
+	Pos token.Pos
+}
+
+func (b *Break) Type() ObjectType { return BREAK }
+func (b *Break) Inspect() string  { return "break" }
+
+type Continue struct {
+	Pos token.Pos
+}
+
+func (c *Continue) Type() ObjectType { return CONTINUE }
+func (c *Continue) Inspect() string  { return "continue" }
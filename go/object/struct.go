@@ -0,0 +1,46 @@
+package object
+
+import (
+	"bytes"
+	"strings"
+)
+
+// Struct is a declared record type's descriptor: its name and the fixed,
+// ordered list of fields every instance must provide. It's registered in an
+// Environment under its own name, the same way a Function value is.
+type Struct struct {
+	Name   string
+	Fields []string
+}
+
+func (s *Struct) Type() ObjectType { return STRUCT }
+func (s *Struct) Inspect() string {
+	return "struct " + s.Name + " { " + strings.Join(s.Fields, ", ") + " }"
+}
+
+// StructInstance is a value built from a Struct descriptor via a struct
+// literal. Fields are looked up by name rather than by the descriptor's
+// index so field access stays simple, at the cost of an extra map per
+// instance.
+type StructInstance struct {
+	Struct *Struct
+	Fields map[string]Object
+}
+
+func (si *StructInstance) Type() ObjectType { return STRUCT_INSTANCE }
+func (si *StructInstance) Inspect() string {
+	var out bytes.Buffer
+
+	out.WriteString(si.Struct.Name)
+	out.WriteString("{ ")
+
+	fields := []string{}
+	for _, name := range si.Struct.Fields {
+		fields = append(fields, name+": "+si.Fields[name].Inspect())
+	}
+	out.WriteString(strings.Join(fields, ", "))
+
+	out.WriteString(" }")
+
+	return out.String()
+}
@@ -0,0 +1,134 @@
+package parser
+
+import (
+	"fmt"
+	"gorb/ast"
+	"gorb/object"
+)
+
+// Eval is the evaluator entry point ExpandMacros uses to run a macro's
+// body. It's set by run.go at program startup, since parser can't import
+// gorb/evaluator directly without creating an import cycle (evaluator's
+// own tests import parser to build test input).
+var Eval func(node ast.Node, env *object.Environment) object.Object
+
+// DefineMacros walks program's top-level statements, registers every
+// `let name = macro(...) {...}` declaration as an *object.Macro in env, and
+// removes those declarations from program so ExpandMacros never sees them
+// as ordinary calls to "name".
+func DefineMacros(program *ast.Program, env *object.Environment) {
+	definitions := []int{}
+
+	for i, stmt := range program.Statements {
+		if isMacroDefinition(stmt) {
+			addMacro(stmt, env)
+			definitions = append(definitions, i)
+		}
+	}
+
+	for i := len(definitions) - 1; i >= 0; i-- {
+		definitionIndex := definitions[i]
+		program.Statements = append(
+			program.Statements[:definitionIndex],
+			program.Statements[definitionIndex+1:]...,
+		)
+	}
+}
+
+func isMacroDefinition(node ast.Statement) bool {
+	declarationStatement, ok := node.(*ast.DeclarationStatement)
+	if !ok {
+		return false
+	}
+
+	_, ok = declarationStatement.Value.(*ast.MacroLiteral)
+	return ok
+}
+
+func addMacro(stmt ast.Statement, env *object.Environment) {
+	declarationStatement, _ := stmt.(*ast.DeclarationStatement)
+	macroLiteral, _ := declarationStatement.Value.(*ast.MacroLiteral)
+
+	macro := &object.Macro{
+		Parameters: macroLiteral.Parameters,
+		Body:       macroLiteral.Body,
+		Env:        env,
+	}
+
+	env.Set(declarationStatement.Name.Value, macro)
+}
+
+// ExpandMacros walks program, replacing every call to a macro registered by
+// DefineMacros with the result of evaluating that macro's body against its
+// arguments quoted (unevaluated), then splicing the returned quote's node
+// back into the tree in the call's place.
+func ExpandMacros(program ast.Node, env *object.Environment) ast.Node {
+	return ast.Modify(program, func(node ast.Node) ast.Node {
+		callExpression, ok := node.(*ast.CallExpression)
+		if !ok {
+			return node
+		}
+
+		macro, ok := isMacroCall(callExpression, env)
+		if !ok {
+			return node
+		}
+
+		args := quoteArgs(callExpression)
+		if len(args) != len(macro.Parameters) {
+			panic(fmt.Sprintf(
+				"wrong number of arguments to macro: want=%d, got=%d",
+				len(macro.Parameters), len(args),
+			))
+		}
+		evalEnv := extendMacroEnv(macro, args)
+
+		evaluated := Eval(macro.Body, evalEnv)
+
+		quote, ok := evaluated.(*object.Quote)
+		if !ok {
+			panic("we only support returning AST-nodes from macros")
+		}
+
+		return quote.Node
+	})
+}
+
+func isMacroCall(exp *ast.CallExpression, env *object.Environment) (*object.Macro, bool) {
+	identifier, ok := exp.Function.(*ast.Identifier)
+	if !ok {
+		return nil, false
+	}
+
+	obj, ok := env.Get(identifier.Value)
+	if !ok {
+		return nil, false
+	}
+
+	macro, ok := obj.(*object.Macro)
+	if !ok {
+		return nil, false
+	}
+
+	return macro, true
+}
+
+func quoteArgs(exp *ast.CallExpression) []*object.Quote {
+	args := make([]*object.Quote, 0, len(exp.Arguments))
+
+	for _, a := range exp.Arguments {
+		args = append(args, &object.Quote{Node: a})
+	}
+
+	return args
+}
+
+func extendMacroEnv(macro *object.Macro, args []*object.Quote) *object.Environment {
+	extended := object.NewEnclosedEnvironment(macro.Env)
+
+	for paramIdx, param := range macro.Parameters {
+		extended.Set(param.Value, args[paramIdx])
+	}
+
+	return extended
+}
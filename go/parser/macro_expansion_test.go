@@ -0,0 +1,98 @@
+package parser
+
+import (
+	"gorb/ast"
+	"gorb/evaluator"
+	"gorb/lexer"
+	"gorb/object"
+	"testing"
+)
+
+func TestDefineMacros(t *testing.T) {
+	input := `
+		let number = 1;
+		let function = fn(x, y) { x + y };
+		let myMacro = macro(x, y) { x + y; };
+	`
+
+	env := object.NewEnvironment()
+	program := testParseProgram(t, input)
+
+	DefineMacros(program, env)
+
+	if len(program.Statements) != 2 {
+		t.Fatalf("wrong number of statements. got=%d", len(program.Statements))
+	}
+
+	if _, ok := env.Get("number"); ok {
+		t.Fatalf("number should not be defined")
+	}
+	if _, ok := env.Get("function"); ok {
+		t.Fatalf("function should not be defined")
+	}
+
+	obj, ok := env.Get("myMacro")
+	if !ok {
+		t.Fatalf("macro not in environment")
+	}
+
+	macro, ok := obj.(*object.Macro)
+	if !ok {
+		t.Fatalf("object is not Macro. got=%T", obj)
+	}
+
+	if len(macro.Parameters) != 2 {
+		t.Fatalf("wrong number of macro parameters. got=%d", len(macro.Parameters))
+	}
+	if macro.Parameters[0].String() != "x" || macro.Parameters[1].String() != "y" {
+		t.Fatalf("wrong parameters. got=%+v", macro.Parameters)
+	}
+	if macro.Body.String() != "(x + y)" {
+		t.Fatalf("wrong macro body. got=%q", macro.Body.String())
+	}
+}
+
+func TestExpandMacros(t *testing.T) {
+	Eval = evaluator.Eval
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{
+			input: `
+				let infixExpression = macro() { quote(1 + 2); };
+				infixExpression();
+			`,
+			expected: `(1 + 2)`,
+		},
+		{
+			input: `
+				let reverse = macro(a, b) { quote(unquote(b) - unquote(a)); };
+				reverse(2 + 2, 10 - 5);
+			`,
+			expected: `(10 - 5) - (2 + 2)`,
+		},
+	}
+
+	for _, tt := range tests {
+		expected := testParseProgram(t, tt.expected)
+		program := testParseProgram(t, tt.input)
+
+		env := object.NewEnvironment()
+		DefineMacros(program, env)
+		expanded := ExpandMacros(program, env)
+
+		if expanded.String() != expected.String() {
+			t.Errorf("not equal. want=%q, got=%q", expected.String(), expanded.String())
+		}
+	}
+}
+
+func testParseProgram(t *testing.T, input string) *ast.Program {
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+	return program
+}
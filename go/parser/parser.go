@@ -5,14 +5,44 @@ import (
 	"gorb/ast"
 	"gorb/lexer"
 	"gorb/token"
+	"io"
 	"strconv"
 )
 
+// DefaultMaxErrors caps how many syntax errors New's parser collects before
+// giving up, so a badly malformed file can't cascade into an unbounded
+// error list.
+const DefaultMaxErrors = 50
+
 func New(l *lexer.Lexer) *Parser {
+	return newParser(l, 0, nil)
+}
+
+// NewWithMode is New with optional behaviors enabled via mode, e.g. Trace.
+func NewWithMode(l *lexer.Lexer, mode Mode) *Parser {
+	return newParser(l, mode, nil)
+}
+
+// NewWithHandler is New with an ErrorHandler that's called for every syntax
+// error the parser produces, so a caller can report errors with real
+// position data instead of collecting formatted strings. If h is nil, the
+// parser falls back to its own collecting handler, exposed via Errors.
+func NewWithHandler(l *lexer.Lexer, h token.ErrorHandler) *Parser {
+	return newParser(l, 0, h)
+}
+
+func newParser(l *lexer.Lexer, mode Mode, h token.ErrorHandler) *Parser {
 	p := &Parser{
-		l:      l,
-		errors: []string{},
+		l:         l,
+		MaxErrors: DefaultMaxErrors,
+		Mode:      mode,
+	}
+
+	if h == nil {
+		p.errors = &token.CollectingHandler{}
+		h = p.errors
 	}
+	p.handler = h
 
 	p.unaryParseFns = make(map[token.TokenType]unaryParseFn)
 	p.registerUnary(token.PAREN_OPEN, p.parseGroupedExpression)
@@ -20,10 +50,14 @@ func New(l *lexer.Lexer) *Parser {
 	p.registerUnary(token.BANG, p.parseUnaryExpression)
 	p.registerUnary(token.MINUS, p.parseUnaryExpression)
 	p.registerUnary(token.FUNCTION, p.parseFunctionLiteral)
+	p.registerUnary(token.MACRO, p.parseMacroLiteral)
+	p.registerUnary(token.QUOTE, p.parseQuoteExpression)
+	p.registerUnary(token.UNQUOTE, p.parseIdentifier)
 	p.registerUnary(token.IDENTIFIER, p.parseIdentifier)
 	p.registerUnary(token.TRUE, p.parseBoolean)
 	p.registerUnary(token.FALSE, p.parseBoolean)
 	p.registerUnary(token.INTEGER, p.parseIntegerLiteral)
+	p.registerUnary(token.FLOAT, p.parseFloatLiteral)
 	p.registerUnary(token.STRING, p.parseStringLiteral)
 	p.registerUnary(token.BRACKET_OPEN, p.parseArrayLiteral)
 	p.registerUnary(token.BRACE_OPEN, p.parseHashLiteral)
@@ -31,6 +65,8 @@ func New(l *lexer.Lexer) *Parser {
 	p.binaryParseFns = make(map[token.TokenType]binaryParseFn)
 	p.registerBinary(token.BRACKET_OPEN, p.parseIndexExpression)
 	p.registerBinary(token.PAREN_OPEN, p.parseCallExpression)
+	p.registerBinary(token.BRACE_OPEN, p.parseStructLiteral)
+	p.registerBinary(token.DOT, p.parseFieldAccessExpression)
 	p.registerBinary(token.PLUS, p.parseBinaryExpression)
 	p.registerBinary(token.MINUS, p.parseBinaryExpression)
 	p.registerBinary(token.SLASH, p.parseBinaryExpression)
@@ -47,14 +83,43 @@ func New(l *lexer.Lexer) *Parser {
 }
 
 type Parser struct {
-	l      *lexer.Lexer
-	errors []string
+	l *lexer.Lexer
+
+	// handler receives every syntax error the parser encounters.
+	handler token.ErrorHandler
+	// errors is non-nil only when newParser installed the default handler
+	// (i.e. no custom ErrorHandler was supplied), so Errors can still expose
+	// the collected list.
+	errors *token.CollectingHandler
+	// errorCount tracks how many errors have been reported so far, since a
+	// custom handler doesn't expose a length the way errors does.
+	errorCount int
+
+	// MaxErrors caps how many syntax errors ParseProgram collects before it
+	// stops parsing altogether, rather than chasing a cascade of errors
+	// caused by one badly malformed construct. Zero means unlimited.
+	MaxErrors int
+
+	// Mode holds the optional behaviors this parser was constructed with,
+	// e.g. Trace. See NewWithMode.
+	Mode Mode
+
+	// TraceOut is where Trace mode writes its output. Defaults to
+	// os.Stdout; set before parsing to redirect it, e.g. in tests.
+	TraceOut io.Writer
+
+	traceIndent int
 
 	curToken  token.Token
 	peekToken token.Token
 
 	unaryParseFns  map[token.TokenType]unaryParseFn
 	binaryParseFns map[token.TokenType]binaryParseFn
+
+	// pendingComments holds CommentGroups collected since they were last
+	// attached to a node, in source order. Only populated in ParseComments
+	// mode. See parser_comments.go.
+	pendingComments []*ast.CommentGroup
 }
 
 type (
@@ -72,7 +137,20 @@ func (p *Parser) registerBinary(tt token.TokenType, fn binaryParseFn) {
 
 func (p *Parser) nextToken() {
 	p.curToken = p.peekToken
-	p.peekToken = p.l.NextToken()
+	p.peekToken = p.nextRealToken()
+}
+
+// nextRealToken pulls tokens from the lexer until it finds one that isn't a
+// comment, recording each comment it skips past along the way. Comments
+// never reach curToken/peekToken, so the rest of the parser never has to
+// think about them.
+func (p *Parser) nextRealToken() token.Token {
+	tok := p.l.NextToken()
+	for tok.Type == token.COMMENT {
+		p.recordComment(tok)
+		tok = p.l.NextToken()
+	}
+	return tok
 }
 
 func (p *Parser) ParseProgram() *ast.Program {
@@ -80,16 +158,52 @@ func (p *Parser) ParseProgram() *ast.Program {
 	program.Statements = []ast.Statement{}
 
 	for !p.curTokenIs(token.EOF) {
+		errCount := p.errorCount
 		stmt := p.parseStatement()
 		if stmt != nil {
 			program.Statements = append(program.Statements, stmt)
 		}
-		p.nextToken()
+
+		if p.errorCount > errCount {
+			p.recover()
+		} else {
+			p.nextToken()
+		}
+
+		if p.MaxErrors > 0 && p.errorCount >= p.MaxErrors {
+			break
+		}
 	}
 
+	program.Comments = p.pendingComments
+
 	return program
 }
 
+// recover resumes parsing after a syntax error so ParseProgram can collect
+// every error in a file in one run instead of bailing out at the first one.
+// It advances past tokens until it reaches one that's safe to resume at: a
+// semicolon, which ends whatever construct went wrong, or the first token of
+// a new statement, so a single bad construct doesn't cascade into a wall of
+// unrelated follow-on errors.
+func (p *Parser) recover() {
+	for !p.curTokenIs(token.EOF) {
+		if p.curTokenIs(token.SEMICOLON) {
+			p.nextToken()
+			return
+		}
+
+		switch p.peekToken.Type {
+		case token.DECLARATION, token.FUNCTION, token.IF, token.WHILE, token.FOR,
+			token.RETURN, token.BREAK, token.CONTINUE, token.BRACE_CLOSE:
+			p.nextToken()
+			return
+		}
+
+		p.nextToken()
+	}
+}
+
 // statements
 
 func (p *Parser) parseStatement() ast.Statement {
@@ -98,12 +212,142 @@ func (p *Parser) parseStatement() ast.Statement {
 		return p.parseReturnStatement()
 	case token.DECLARATION:
 		return p.parseDeclarationStatement()
+	case token.WHILE:
+		return p.parseWhileStatement()
+	case token.FOR:
+		return p.parseForStatement()
+	case token.BREAK:
+		return p.parseBreakStatement()
+	case token.CONTINUE:
+		return p.parseContinueStatement()
+	case token.STRUCT:
+		return p.parseStructDeclaration()
+	case token.IDENTIFIER:
+		if p.peekTokenIs(token.ASSIGN) {
+			return p.parseAssignStatement()
+		}
+		return p.parseExpressionStatement()
 	default:
 		return p.parseExpressionStatement()
 	}
 }
 
+// parseAssignStatement parses `name = value;`, updating a binding that
+// already exists rather than introducing a new one the way let does.
+func (p *Parser) parseAssignStatement() *ast.AssignStatement {
+	defer p.untrace(p.trace("parseAssignStatement"))
+	stmt := &ast.AssignStatement{
+		Token: p.curToken,
+		Name:  &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal},
+	}
+
+	if !p.expectPeek(token.ASSIGN) {
+		return nil
+	}
+
+	p.nextToken()
+
+	stmt.Value = p.parseExpression(LOWEST)
+
+	for p.peekTokenIs(token.SEMICOLON) {
+		p.nextToken()
+	}
+
+	return stmt
+}
+
+func (p *Parser) parseWhileStatement() *ast.WhileStatement {
+	defer p.untrace(p.trace("parseWhileStatement"))
+	stmt := &ast.WhileStatement{Token: p.curToken}
+
+	if !p.expectPeek(token.PAREN_OPEN) {
+		return nil
+	}
+
+	p.nextToken()
+	stmt.Condition = p.parseExpression(LOWEST)
+
+	if !p.expectPeek(token.PAREN_CLOSE) {
+		return nil
+	}
+
+	if !p.expectPeek(token.BRACE_OPEN) {
+		return nil
+	}
+
+	stmt.Body = p.parseBlockStatement()
+
+	return stmt
+}
+
+// parseForStatement parses the classic three-clause `for (init; cond; post)
+// { ... }` loop; each clause is optional, same as in C.
+func (p *Parser) parseForStatement() *ast.ForStatement {
+	defer p.untrace(p.trace("parseForStatement"))
+	stmt := &ast.ForStatement{Token: p.curToken}
+
+	if !p.expectPeek(token.PAREN_OPEN) {
+		return nil
+	}
+
+	if p.peekTokenIs(token.SEMICOLON) {
+		p.nextToken()
+	} else {
+		p.nextToken()
+		stmt.Init = p.parseStatement()
+	}
+
+	p.nextToken()
+
+	if !p.curTokenIs(token.SEMICOLON) {
+		stmt.Condition = p.parseExpression(LOWEST)
+		if !p.expectPeek(token.SEMICOLON) {
+			return nil
+		}
+	}
+
+	p.nextToken()
+
+	if !p.curTokenIs(token.PAREN_CLOSE) {
+		stmt.Post = p.parseStatement()
+		if !p.expectPeek(token.PAREN_CLOSE) {
+			return nil
+		}
+	}
+
+	if !p.expectPeek(token.BRACE_OPEN) {
+		return nil
+	}
+
+	stmt.Body = p.parseBlockStatement()
+
+	return stmt
+}
+
+func (p *Parser) parseBreakStatement() *ast.BreakStatement {
+	defer p.untrace(p.trace("parseBreakStatement"))
+	stmt := &ast.BreakStatement{Token: p.curToken}
+
+	for p.peekTokenIs(token.SEMICOLON) {
+		p.nextToken()
+	}
+
+	return stmt
+}
+
+func (p *Parser) parseContinueStatement() *ast.ContinueStatement {
+	defer p.untrace(p.trace("parseContinueStatement"))
+	stmt := &ast.ContinueStatement{Token: p.curToken}
+
+	for p.peekTokenIs(token.SEMICOLON) {
+		p.nextToken()
+	}
+
+	return stmt
+}
+
 func (p *Parser) parseReturnStatement() *ast.ReturnStatement {
+	defer p.untrace(p.trace("parseReturnStatement"))
 	stmt := &ast.ReturnStatement{Token: p.curToken}
 
 	p.nextToken()
@@ -118,7 +362,8 @@ func (p *Parser) parseReturnStatement() *ast.ReturnStatement {
 }
 
 func (p *Parser) parseDeclarationStatement() *ast.DeclarationStatement {
-	stmt := &ast.DeclarationStatement{Token: p.curToken}
+	defer p.untrace(p.trace("parseDeclarationStatement"))
+	stmt := &ast.DeclarationStatement{Token: p.curToken, Doc: p.leadingComment(p.curToken.Pos.Ln)}
 
 	if !p.expectPeek(token.IDENTIFIER) {
 		return nil
@@ -126,6 +371,11 @@ func (p *Parser) parseDeclarationStatement() *ast.DeclarationStatement {
 
 	stmt.Name = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
 
+	if p.peekTokenIs(token.COLON) {
+		p.nextToken()
+		stmt.TypeAnnotation = p.parseTypeAnnotation()
+	}
+
 	if !p.expectPeek(token.ASSIGN) {
 		return nil
 	}
@@ -141,30 +391,105 @@ func (p *Parser) parseDeclarationStatement() *ast.DeclarationStatement {
 	return stmt
 }
 
+// parseStructDeclaration parses `struct Name { field: type, ... }`.
+func (p *Parser) parseStructDeclaration() *ast.StructDeclaration {
+	defer p.untrace(p.trace("parseStructDeclaration"))
+	stmt := &ast.StructDeclaration{Token: p.curToken, Doc: p.leadingComment(p.curToken.Pos.Ln)}
+
+	if !p.expectPeek(token.IDENTIFIER) {
+		return nil
+	}
+	stmt.Name = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+
+	if !p.expectPeek(token.BRACE_OPEN) {
+		return nil
+	}
+
+	for !p.peekTokenIs(token.BRACE_CLOSE) {
+		p.nextToken()
+
+		if !p.curTokenIs(token.IDENTIFIER) {
+			msg := fmt.Sprintf("expected field name, got %s", p.curToken.Type)
+			p.reportError(p.curToken.Pos, msg)
+			return nil
+		}
+		field := &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+		stmt.Fields = append(stmt.Fields, field)
+
+		var fieldType *ast.TypeAnnotation
+		if p.peekTokenIs(token.COLON) {
+			p.nextToken()
+			fieldType = p.parseTypeAnnotation()
+		}
+		stmt.FieldTypes = append(stmt.FieldTypes, fieldType)
+
+		if !p.peekTokenIs(token.BRACE_CLOSE) && !p.expectPeek(token.COMMA) {
+			return nil
+		}
+	}
+
+	if !p.expectPeek(token.BRACE_CLOSE) {
+		return nil
+	}
+
+	for p.peekTokenIs(token.SEMICOLON) {
+		p.nextToken()
+	}
+
+	return stmt
+}
+
+// parseTypeAnnotation expects curToken to be the colon introducing the
+// annotation and consumes the identifier naming the type.
+func (p *Parser) parseTypeAnnotation() *ast.TypeAnnotation {
+	defer p.untrace(p.trace("parseTypeAnnotation"))
+	if !p.expectPeek(token.IDENTIFIER) {
+		return nil
+	}
+
+	return &ast.TypeAnnotation{Token: p.curToken, Name: p.curToken.Literal}
+}
+
 func (p *Parser) parseExpressionStatement() *ast.ExpressionStatement {
+	defer p.untrace(p.trace("parseExpressionStatement"))
 	stmt := &ast.ExpressionStatement{Token: p.curToken}
 
 	stmt.Expression = p.parseExpression(LOWEST)
+	line := p.curToken.Pos.Ln
 
 	for p.peekTokenIs(token.SEMICOLON) {
 		p.nextToken()
+		line = p.curToken.Pos.Ln
 	}
 
+	stmt.Comment = p.trailingComment(line)
+
 	return stmt
 }
 
 func (p *Parser) parseBlockStatement() *ast.BlockStatement {
+	defer p.untrace(p.trace("parseBlockStatement"))
 	block := &ast.BlockStatement{Token: p.curToken}
 	block.Statements = []ast.Statement{}
 
 	p.nextToken()
 
 	for !p.curTokenIs(token.BRACE_CLOSE) && !p.curTokenIs(token.EOF) {
+		errCount := p.errorCount
 		stmt := p.parseStatement()
 		if stmt != nil {
 			block.Statements = append(block.Statements, stmt)
 		}
-		p.nextToken()
+
+		if p.errorCount > errCount {
+			p.recover()
+		} else {
+			p.nextToken()
+		}
+
+		if p.MaxErrors > 0 && p.errorCount >= p.MaxErrors {
+			break
+		}
 	}
 
 	return block
@@ -173,6 +498,7 @@ func (p *Parser) parseBlockStatement() *ast.BlockStatement {
 // expressions
 
 func (p *Parser) parseGroupedExpression() ast.Expression {
+	defer p.untrace(p.trace("parseGroupedExpression"))
 	p.nextToken()
 
 	exp := p.parseExpression(LOWEST)
@@ -185,6 +511,7 @@ func (p *Parser) parseGroupedExpression() ast.Expression {
 }
 
 func (p *Parser) parseIndexExpression(left ast.Expression) ast.Expression {
+	defer p.untrace(p.trace("parseIndexExpression"))
 	exp := &ast.IndexExpression{Token: p.curToken, Left: left}
 
 	p.nextToken()
@@ -197,7 +524,20 @@ func (p *Parser) parseIndexExpression(left ast.Expression) ast.Expression {
 	return exp
 }
 
+func (p *Parser) parseFieldAccessExpression(left ast.Expression) ast.Expression {
+	defer p.untrace(p.trace("parseFieldAccessExpression"))
+	exp := &ast.FieldAccessExpression{Token: p.curToken, Left: left}
+
+	if !p.expectPeek(token.IDENTIFIER) {
+		return nil
+	}
+	exp.Field = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+
+	return exp
+}
+
 func (p *Parser) parseCallExpression(function ast.Expression) ast.Expression {
+	defer p.untrace(p.trace("parseCallExpression"))
 	exp := &ast.CallExpression{Token: p.curToken, Function: function}
 	exp.Arguments = p.parseExpressionList(token.PAREN_CLOSE)
 
@@ -205,6 +545,7 @@ func (p *Parser) parseCallExpression(function ast.Expression) ast.Expression {
 }
 
 func (p *Parser) parseIfExpression() ast.Expression {
+	defer p.untrace(p.trace("parseIfExpression"))
 	exp := &ast.IfExpression{Token: p.curToken}
 
 	if !p.expectPeek(token.PAREN_OPEN) {
@@ -238,6 +579,7 @@ func (p *Parser) parseIfExpression() ast.Expression {
 }
 
 func (p *Parser) parseExpression(precedence int) ast.Expression {
+	defer p.untrace(p.trace("parseExpression"))
 	parseUnary := p.unaryParseFns[p.curToken.Type]
 	if parseUnary == nil {
 		p.noUnaryParseFnError(p.curToken.Type)
@@ -246,6 +588,16 @@ func (p *Parser) parseExpression(precedence int) ast.Expression {
 	leftExp := parseUnary()
 
 	for !p.peekTokenIs(token.SEMICOLON) && precedence < p.peekPrecedence() {
+		// A `{` only starts a struct literal when it directly follows the
+		// struct's name; anything else (e.g. an if-expression statement
+		// immediately followed by a `{`-led statement on the next line)
+		// must not be swallowed into a bogus struct-literal attempt.
+		if p.peekTokenIs(token.BRACE_OPEN) {
+			if _, ok := leftExp.(*ast.Identifier); !ok {
+				return leftExp
+			}
+		}
+
 		parseBinary := p.binaryParseFns[p.peekToken.Type]
 		if parseBinary == nil {
 			return leftExp
@@ -260,6 +612,7 @@ func (p *Parser) parseExpression(precedence int) ast.Expression {
 }
 
 func (p *Parser) parseUnaryExpression() ast.Expression {
+	defer p.untrace(p.trace("parseUnaryExpression"))
 	exp := &ast.UnaryExpression{
 		Token:    p.curToken,
 		Operator: p.curToken.Type,
@@ -273,6 +626,7 @@ func (p *Parser) parseUnaryExpression() ast.Expression {
 }
 
 func (p *Parser) parseBinaryExpression(left ast.Expression) ast.Expression {
+	defer p.untrace(p.trace("parseBinaryExpression"))
 	exp := &ast.BinaryExpression{
 		Token:    p.curToken,
 		Operator: p.curToken.Type,
@@ -289,13 +643,23 @@ func (p *Parser) parseBinaryExpression(left ast.Expression) ast.Expression {
 // literals
 
 func (p *Parser) parseFunctionLiteral() ast.Expression {
-	lit := &ast.FunctionLiteral{Token: p.curToken}
+	defer p.untrace(p.trace("parseFunctionLiteral"))
+	lit := &ast.FunctionLiteral{Token: p.curToken, Doc: p.leadingComment(p.curToken.Pos.Ln)}
 
 	if !p.expectPeek(token.PAREN_OPEN) {
 		return nil
 	}
 
-	lit.Parameters = p.parseFunctionParameters()
+	lit.Parameters, lit.ParamTypes = p.parseFunctionParameters()
+
+	if !p.expectPeek(token.PAREN_CLOSE) {
+		return nil
+	}
+
+	if p.peekTokenIs(token.COLON) {
+		p.nextToken()
+		lit.ReturnType = p.parseTypeAnnotation()
+	}
 
 	if !p.expectPeek(token.BRACE_OPEN) {
 		return nil
@@ -306,53 +670,142 @@ func (p *Parser) parseFunctionLiteral() ast.Expression {
 	return lit
 }
 
-func (p *Parser) parseFunctionParameters() []*ast.Identifier {
+func (p *Parser) parseFunctionParameters() ([]*ast.Identifier, []*ast.TypeAnnotation) {
 	identifiers := []*ast.Identifier{}
+	types := []*ast.TypeAnnotation{}
 
 	if p.peekTokenIs(token.PAREN_CLOSE) {
-		p.nextToken()
-		return identifiers
+		return identifiers, types
 	}
 
 	p.nextToken()
-
-	ident := &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+	ident, typ := p.parseFunctionParameter()
 	identifiers = append(identifiers, ident)
+	types = append(types, typ)
 
 	for p.peekTokenIs(token.COMMA) {
 		p.nextToken()
 		p.nextToken()
-		ident := &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+		ident, typ := p.parseFunctionParameter()
 		identifiers = append(identifiers, ident)
+		types = append(types, typ)
 	}
 
+	return identifiers, types
+}
+
+// parseMacroLiteral parses `macro(params) { body }`. Unlike function
+// literals, its parameters carry no type annotations.
+func (p *Parser) parseMacroLiteral() ast.Expression {
+	defer p.untrace(p.trace("parseMacroLiteral"))
+	lit := &ast.MacroLiteral{Token: p.curToken}
+
+	if !p.expectPeek(token.PAREN_OPEN) {
+		return nil
+	}
+
+	lit.Parameters = p.parseMacroParameters()
+
 	if !p.expectPeek(token.PAREN_CLOSE) {
 		return nil
 	}
 
+	if !p.expectPeek(token.BRACE_OPEN) {
+		return nil
+	}
+
+	lit.Body = p.parseBlockStatement()
+
+	return lit
+}
+
+func (p *Parser) parseMacroParameters() []*ast.Identifier {
+	identifiers := []*ast.Identifier{}
+
+	if p.peekTokenIs(token.PAREN_CLOSE) {
+		return identifiers
+	}
+
+	p.nextToken()
+	identifiers = append(identifiers, &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal})
+
+	for p.peekTokenIs(token.COMMA) {
+		p.nextToken()
+		p.nextToken()
+		identifiers = append(identifiers, &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal})
+	}
+
 	return identifiers
 }
 
+// parseQuoteExpression parses `quote(expr)`, capturing expr as an
+// unevaluated AST node rather than parsing it as an ordinary call argument.
+func (p *Parser) parseQuoteExpression() ast.Expression {
+	defer p.untrace(p.trace("parseQuoteExpression"))
+	exp := &ast.QuoteExpression{Token: p.curToken}
+
+	if !p.expectPeek(token.PAREN_OPEN) {
+		return nil
+	}
+
+	p.nextToken()
+	exp.Node = p.parseExpression(LOWEST)
+
+	if !p.expectPeek(token.PAREN_CLOSE) {
+		return nil
+	}
+
+	return exp
+}
+
+func (p *Parser) parseFunctionParameter() (*ast.Identifier, *ast.TypeAnnotation) {
+	ident := &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+
+	var typ *ast.TypeAnnotation
+	if p.peekTokenIs(token.COLON) {
+		p.nextToken()
+		typ = p.parseTypeAnnotation()
+	}
+
+	return ident, typ
+}
+
 func (p *Parser) parseIdentifier() ast.Expression {
+	defer p.untrace(p.trace("parseIdentifier"))
 	return &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
 }
 
 func (p *Parser) parseBoolean() ast.Expression {
+	defer p.untrace(p.trace("parseBoolean"))
 	return &ast.BooleanLiteral{Token: p.curToken, Value: p.curTokenIs(token.TRUE)}
 }
 
 func (p *Parser) parseIntegerLiteral() ast.Expression {
+	defer p.untrace(p.trace("parseIntegerLiteral"))
 	lit := &ast.IntegerLiteral{Token: p.curToken}
 
 	val, err := strconv.ParseInt(p.curToken.Literal, 0, 64)
 
 	if err != nil {
-		msg := fmt.Sprintf(
-			"%v could not parse %q as integer",
-			p.curToken.Pos,
-			p.curToken.Literal,
-		)
-		p.errors = append(p.errors, msg)
+		msg := fmt.Sprintf("could not parse %q as integer", p.curToken.Literal)
+		p.reportError(p.curToken.Pos, msg)
+		return nil
+	}
+
+	lit.Value = val
+
+	return lit
+}
+
+func (p *Parser) parseFloatLiteral() ast.Expression {
+	defer p.untrace(p.trace("parseFloatLiteral"))
+	lit := &ast.FloatLiteral{Token: p.curToken}
+
+	val, err := strconv.ParseFloat(p.curToken.Literal, 64)
+
+	if err != nil {
+		msg := fmt.Sprintf("could not parse %q as float", p.curToken.Literal)
+		p.reportError(p.curToken.Pos, msg)
 		return nil
 	}
 
@@ -362,10 +815,12 @@ func (p *Parser) parseIntegerLiteral() ast.Expression {
 }
 
 func (p *Parser) parseStringLiteral() ast.Expression {
+	defer p.untrace(p.trace("parseStringLiteral"))
 	return &ast.StringLiteral{Token: p.curToken, Value: p.curToken.Literal}
 }
 
 func (p *Parser) parseArrayLiteral() ast.Expression {
+	defer p.untrace(p.trace("parseArrayLiteral"))
 	array := &ast.ArrayLiteral{Token: p.curToken}
 
 	array.Elements = p.parseExpressionList(token.BRACKET_CLOSE)
@@ -398,6 +853,7 @@ func (p *Parser) parseExpressionList(end token.TokenType) []ast.Expression {
 }
 
 func (p *Parser) parseHashLiteral() ast.Expression {
+	defer p.untrace(p.trace("parseHashLiteral"))
 	hash := &ast.HashLiteral{Token: p.curToken}
 
 	hash.Pairs = make(map[ast.Expression]ast.Expression)
@@ -427,6 +883,48 @@ func (p *Parser) parseHashLiteral() ast.Expression {
 	return hash
 }
 
+// parseStructLiteral parses `Name{ field: value, ... }`; left must be the
+// identifier naming the struct being constructed.
+func (p *Parser) parseStructLiteral(left ast.Expression) ast.Expression {
+	defer p.untrace(p.trace("parseStructLiteral"))
+	name, ok := left.(*ast.Identifier)
+	if !ok {
+		msg := fmt.Sprintf("expected a struct name before '{', got %s", left.String())
+		p.reportError(p.curToken.Pos, msg)
+		return nil
+	}
+
+	lit := &ast.StructLiteral{Token: p.curToken, Name: name, Fields: make(map[string]ast.Expression)}
+
+	for !p.peekTokenIs(token.BRACE_CLOSE) {
+		p.nextToken()
+
+		if !p.curTokenIs(token.IDENTIFIER) {
+			msg := fmt.Sprintf("expected field name, got %s", p.curToken.Type)
+			p.reportError(p.curToken.Pos, msg)
+			return nil
+		}
+		fieldName := p.curToken.Literal
+
+		if !p.expectPeek(token.COLON) {
+			return nil
+		}
+
+		p.nextToken()
+		lit.Fields[fieldName] = p.parseExpression(LOWEST)
+
+		if !p.peekTokenIs(token.BRACE_CLOSE) && !p.expectPeek(token.COMMA) {
+			return nil
+		}
+	}
+
+	if !p.expectPeek(token.BRACE_CLOSE) {
+		return nil
+	}
+
+	return lit
+}
+
 // helpers
 
 func (p *Parser) curTokenIs(tt token.TokenType) bool {
@@ -470,6 +968,8 @@ var precedences = map[token.TokenType]int{
 	token.SLASH:        PRODUCT,
 	token.PAREN_OPEN:   CALL,
 	token.BRACKET_OPEN: INDEX,
+	token.BRACE_OPEN:   CALL,
+	token.DOT:          INDEX,
 }
 
 func (p *Parser) peekPrecedence() int {
@@ -490,25 +990,30 @@ func (p *Parser) curPrecedence() int {
 
 // errors
 
-func (p *Parser) Errors() []string {
-	return p.errors
+// Errors returns the errors collected by the parser's default handler. It's
+// empty whenever a custom ErrorHandler was installed via NewWithHandler,
+// since those errors went to the handler instead of being collected here.
+func (p *Parser) Errors() token.ErrorList {
+	if p.errors == nil {
+		return nil
+	}
+	return p.errors.Errors
+}
+
+// reportError forwards a syntax error to the parser's handler and counts it,
+// so ParseProgram and parseBlockStatement can detect a cascade and MaxErrors
+// can cap collection, regardless of which handler is installed.
+func (p *Parser) reportError(pos token.Pos, msg string) {
+	p.errorCount++
+	p.handler.Error(pos, msg)
 }
 
 func (p *Parser) peekError(t token.TokenType) {
-	msg := fmt.Sprintf(
-		"%v expected %s, got %s",
-		p.curToken.Pos,
-		t,
-		p.peekToken.Type,
-	)
-	p.errors = append(p.errors, msg)
+	msg := fmt.Sprintf("expected %s, got %s", t, p.peekToken.Type)
+	p.reportError(p.curToken.Pos, msg)
 }
 
 func (p *Parser) noUnaryParseFnError(t token.TokenType) {
-	msg := fmt.Sprintf(
-		"%v no unary parse function for %s found",
-		p.curToken.Pos,
-		t,
-	)
-	p.errors = append(p.errors, msg)
-}
\ No newline at end of file
+	msg := fmt.Sprintf("no unary parse function for %s found", t)
+	p.reportError(p.curToken.Pos, msg)
+}
@@ -0,0 +1,59 @@
+package parser
+
+import (
+	"gorb/ast"
+	"gorb/token"
+	"strings"
+)
+
+// recordComment appends tok to the last pending CommentGroup if it continues
+// it (no blank line in between), or starts a new group otherwise. It's a
+// no-op outside ParseComments mode.
+func (p *Parser) recordComment(tok token.Token) {
+	if p.Mode&ParseComments == 0 {
+		return
+	}
+
+	endLine := tok.Pos.Ln + strings.Count(tok.Literal, "\n")
+	comment := &ast.Comment{Token: tok, Text: tok.Literal, EndLine: endLine}
+
+	if n := len(p.pendingComments); n > 0 {
+		last := p.pendingComments[n-1]
+		lastEnd := last.List[len(last.List)-1].EndLine
+		if tok.Pos.Ln <= lastEnd+1 {
+			last.List = append(last.List, comment)
+			return
+		}
+	}
+
+	p.pendingComments = append(p.pendingComments, &ast.CommentGroup{List: []*ast.Comment{comment}})
+}
+
+// leadingComment pops and returns the most recently pending CommentGroup if
+// it ends on the line immediately before line, so it reads as documentation
+// for whatever starts there. Returns nil without touching pendingComments
+// otherwise, so an unrelated group can still end up on Program.Comments.
+func (p *Parser) leadingComment(line int) *ast.CommentGroup {
+	if n := len(p.pendingComments); n > 0 {
+		group := p.pendingComments[n-1]
+		if group.List[len(group.List)-1].EndLine == line-1 {
+			p.pendingComments = p.pendingComments[:n-1]
+			return group
+		}
+	}
+	return nil
+}
+
+// trailingComment pops and returns the most recently pending CommentGroup if
+// its first comment starts on line, so it reads as a trailing remark on
+// whatever ended there.
+func (p *Parser) trailingComment(line int) *ast.CommentGroup {
+	if n := len(p.pendingComments); n > 0 {
+		group := p.pendingComments[n-1]
+		if group.List[0].Token.Pos.Ln == line {
+			p.pendingComments = p.pendingComments[:n-1]
+			return group
+		}
+	}
+	return nil
+}
@@ -0,0 +1,455 @@
+package parser
+
+import (
+	"bytes"
+	"gorb/ast"
+	"gorb/lexer"
+	"gorb/token"
+	"strings"
+	"testing"
+)
+
+func checkParserErrors(t *testing.T, p *Parser) {
+	errors := p.Errors()
+	if len(errors) == 0 {
+		return
+	}
+
+	t.Errorf("parser has %d errors", len(errors))
+	for _, msg := range errors {
+		t.Errorf("parser error: %s", msg)
+	}
+	t.FailNow()
+}
+
+func TestWhileStatement(t *testing.T) {
+	input := `while (x < 10) { x; }`
+
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	if len(program.Statements) != 1 {
+		t.Fatalf("program.Statements does not contain 1 statement. got=%d", len(program.Statements))
+	}
+
+	stmt, ok := program.Statements[0].(*ast.WhileStatement)
+	if !ok {
+		t.Fatalf("statement is not WhileStatement. got=%T", program.Statements[0])
+	}
+
+	if len(stmt.Body.Statements) != 1 {
+		t.Fatalf("body does not contain 1 statement. got=%d", len(stmt.Body.Statements))
+	}
+}
+
+func TestForStatement(t *testing.T) {
+	input := `for (let i = 0; i < 10; let i = i + 1) { i; }`
+
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	if len(program.Statements) != 1 {
+		t.Fatalf("program.Statements does not contain 1 statement. got=%d", len(program.Statements))
+	}
+
+	stmt, ok := program.Statements[0].(*ast.ForStatement)
+	if !ok {
+		t.Fatalf("statement is not ForStatement. got=%T", program.Statements[0])
+	}
+
+	if _, ok := stmt.Init.(*ast.DeclarationStatement); !ok {
+		t.Fatalf("stmt.Init is not DeclarationStatement. got=%T", stmt.Init)
+	}
+	if stmt.Condition == nil {
+		t.Fatalf("stmt.Condition is nil")
+	}
+	if _, ok := stmt.Post.(*ast.DeclarationStatement); !ok {
+		t.Fatalf("stmt.Post is not DeclarationStatement. got=%T", stmt.Post)
+	}
+}
+
+func TestForStatementWithOmittedClauses(t *testing.T) {
+	input := `for (;;) { break; }`
+
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	stmt, ok := program.Statements[0].(*ast.ForStatement)
+	if !ok {
+		t.Fatalf("statement is not ForStatement. got=%T", program.Statements[0])
+	}
+
+	if stmt.Init != nil || stmt.Condition != nil || stmt.Post != nil {
+		t.Fatalf("expected all clauses omitted, got Init=%v Condition=%v Post=%v", stmt.Init, stmt.Condition, stmt.Post)
+	}
+	if len(stmt.Body.Statements) != 1 {
+		t.Fatalf("body does not contain 1 statement. got=%d", len(stmt.Body.Statements))
+	}
+	if _, ok := stmt.Body.Statements[0].(*ast.BreakStatement); !ok {
+		t.Fatalf("body statement is not BreakStatement. got=%T", stmt.Body.Statements[0])
+	}
+}
+
+func TestStructDeclaration(t *testing.T) {
+	input := `struct Point { x: int, y: int }`
+
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	stmt, ok := program.Statements[0].(*ast.StructDeclaration)
+	if !ok {
+		t.Fatalf("statement is not StructDeclaration. got=%T", program.Statements[0])
+	}
+	if stmt.Name.Value != "Point" {
+		t.Fatalf("struct name wrong. got=%s", stmt.Name.Value)
+	}
+	if len(stmt.Fields) != 2 || stmt.Fields[0].Value != "x" || stmt.Fields[1].Value != "y" {
+		t.Fatalf("unexpected fields: %+v", stmt.Fields)
+	}
+}
+
+func TestStructLiteralAndFieldAccess(t *testing.T) {
+	input := `Point{ x: 1, y: 2 }.x`
+
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	exprStmt, ok := program.Statements[0].(*ast.ExpressionStatement)
+	if !ok {
+		t.Fatalf("statement is not ExpressionStatement. got=%T", program.Statements[0])
+	}
+
+	access, ok := exprStmt.Expression.(*ast.FieldAccessExpression)
+	if !ok {
+		t.Fatalf("expression is not FieldAccessExpression. got=%T", exprStmt.Expression)
+	}
+	if access.Field.Value != "x" {
+		t.Fatalf("wrong field. got=%s", access.Field.Value)
+	}
+
+	lit, ok := access.Left.(*ast.StructLiteral)
+	if !ok {
+		t.Fatalf("left is not StructLiteral. got=%T", access.Left)
+	}
+	if lit.Name.Value != "Point" || len(lit.Fields) != 2 {
+		t.Fatalf("unexpected struct literal: %+v", lit)
+	}
+}
+
+func TestTraceModePrintsNestedProductions(t *testing.T) {
+	var buf bytes.Buffer
+
+	l := lexer.New("!-a[1]")
+	p := NewWithMode(l, Trace)
+	p.TraceOut = &buf
+	p.ParseProgram()
+
+	out := buf.String()
+
+	for _, want := range []string{
+		"BEGIN parseExpressionStatement",
+		"BEGIN parseUnaryExpression",
+		"BEGIN parseIndexExpression",
+		"BEGIN parseIdentifier",
+		"BEGIN parseIntegerLiteral",
+		"END parseExpressionStatement",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("trace output missing %q, got:\n%s", want, out)
+		}
+	}
+}
+
+func TestWithoutTraceModePrintsNothing(t *testing.T) {
+	var buf bytes.Buffer
+
+	l := lexer.New("!-a[1]")
+	p := New(l)
+	p.TraceOut = &buf
+	p.ParseProgram()
+
+	if buf.Len() != 0 {
+		t.Fatalf("expected no trace output without Trace mode, got:\n%s", buf.String())
+	}
+}
+
+func TestRecoversAfterSyntaxErrorAndCollectsAll(t *testing.T) {
+	input := `
+	let x = ;
+	let y = 5;
+	let z = ;
+	let w = 10;
+	`
+
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+
+	if len(p.Errors()) != 2 {
+		t.Fatalf("expected 2 errors, got %d: %v", len(p.Errors()), p.Errors())
+	}
+
+	if len(program.Statements) != 4 {
+		t.Fatalf("expected all 4 declarations to be parsed (2 with a missing value), got %d", len(program.Statements))
+	}
+
+	y, ok := program.Statements[1].(*ast.DeclarationStatement)
+	if !ok || y.Name.Value != "y" || y.Value == nil {
+		t.Fatalf("expected second statement to be a complete declaration of y, got %+v", program.Statements[1])
+	}
+
+	w, ok := program.Statements[3].(*ast.DeclarationStatement)
+	if !ok || w.Name.Value != "w" || w.Value == nil {
+		t.Fatalf("expected fourth statement to be a complete declaration of w, got %+v", program.Statements[3])
+	}
+}
+
+func TestMaxErrorsStopsParsing(t *testing.T) {
+	input := `let a = ; let b = ; let c = ; let d = ;`
+
+	l := lexer.New(input)
+	p := New(l)
+	p.MaxErrors = 2
+	p.ParseProgram()
+
+	if len(p.Errors()) != 2 {
+		t.Fatalf("expected parsing to stop after 2 errors, got %d: %v", len(p.Errors()), p.Errors())
+	}
+}
+
+func TestNewWithHandlerRoutesErrorsToCustomHandler(t *testing.T) {
+	h := &token.CollectingHandler{}
+
+	l := lexer.New(`let x = ;`)
+	p := NewWithHandler(l, h)
+	p.ParseProgram()
+
+	if len(p.Errors()) != 0 {
+		t.Fatalf("expected Errors() to be empty when a custom handler is installed, got %v", p.Errors())
+	}
+	if len(h.Errors) != 1 {
+		t.Fatalf("expected the custom handler to receive 1 error, got %d: %v", len(h.Errors), h.Errors)
+	}
+}
+
+func TestParseCommentsAttachesLeadingDoc(t *testing.T) {
+	input := `
+	// Point holds x and y.
+	// It's a demo struct.
+	struct Point { x: int, y: int }
+	`
+
+	l := lexer.New(input)
+	p := NewWithMode(l, ParseComments)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	stmt, ok := program.Statements[0].(*ast.StructDeclaration)
+	if !ok {
+		t.Fatalf("statement is not StructDeclaration. got=%T", program.Statements[0])
+	}
+	if stmt.Doc == nil {
+		t.Fatalf("expected a Doc comment group, got nil")
+	}
+	if len(stmt.Doc.List) != 2 {
+		t.Fatalf("expected 2 comments in the doc group, got %d", len(stmt.Doc.List))
+	}
+	want := "// Point holds x and y.\n// It's a demo struct."
+	if stmt.Doc.String() != want {
+		t.Fatalf("unexpected Doc.String():\ngot:  %q\nwant: %q", stmt.Doc.String(), want)
+	}
+}
+
+func TestParseCommentsAttachesTrailingComment(t *testing.T) {
+	input := `x; // trailing`
+
+	l := lexer.New(input)
+	p := NewWithMode(l, ParseComments)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	stmt, ok := program.Statements[0].(*ast.ExpressionStatement)
+	if !ok {
+		t.Fatalf("statement is not ExpressionStatement. got=%T", program.Statements[0])
+	}
+	if stmt.Comment == nil {
+		t.Fatalf("expected a trailing Comment, got nil")
+	}
+	if stmt.Comment.String() != "// trailing" {
+		t.Fatalf("unexpected trailing comment: %q", stmt.Comment.String())
+	}
+}
+
+func TestParseCommentsCollectsUnattachedOnProgram(t *testing.T) {
+	input := `
+	x;
+
+	// floating, detached on both sides
+
+	y;
+	`
+
+	l := lexer.New(input)
+	p := NewWithMode(l, ParseComments)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	if len(program.Comments) != 1 {
+		t.Fatalf("expected 1 unattached comment group, got %d: %+v", len(program.Comments), program.Comments)
+	}
+	if program.Comments[0].String() != "// floating, detached on both sides" {
+		t.Fatalf("unexpected floating comment: %q", program.Comments[0].String())
+	}
+}
+
+func TestWithoutParseCommentsModeCommentsAreIgnored(t *testing.T) {
+	input := `
+	// a doc comment
+	let x = 1; // trailing
+	`
+
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	stmt, ok := program.Statements[0].(*ast.DeclarationStatement)
+	if !ok {
+		t.Fatalf("statement is not DeclarationStatement. got=%T", program.Statements[0])
+	}
+	if stmt.Doc != nil {
+		t.Fatalf("expected no Doc without ParseComments mode, got %v", stmt.Doc)
+	}
+	if len(program.Comments) != 0 {
+		t.Fatalf("expected no collected comments without ParseComments mode, got %v", program.Comments)
+	}
+}
+
+func TestFloatLiteralExpression(t *testing.T) {
+	input := `2.5e-2;`
+
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	exprStmt, ok := program.Statements[0].(*ast.ExpressionStatement)
+	if !ok {
+		t.Fatalf("statement is not ExpressionStatement. got=%T", program.Statements[0])
+	}
+
+	lit, ok := exprStmt.Expression.(*ast.FloatLiteral)
+	if !ok {
+		t.Fatalf("expression is not FloatLiteral. got=%T", exprStmt.Expression)
+	}
+	if lit.Value != 0.025 {
+		t.Fatalf("wrong value. got=%v", lit.Value)
+	}
+}
+
+func TestAssignStatement(t *testing.T) {
+	input := `x = 5;`
+
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	stmt, ok := program.Statements[0].(*ast.AssignStatement)
+	if !ok {
+		t.Fatalf("statement is not AssignStatement. got=%T", program.Statements[0])
+	}
+	if stmt.Name.Value != "x" {
+		t.Fatalf("wrong name. got=%s", stmt.Name.Value)
+	}
+
+	lit, ok := stmt.Value.(*ast.IntegerLiteral)
+	if !ok || lit.Value != 5 {
+		t.Fatalf("wrong value. got=%+v", stmt.Value)
+	}
+}
+
+func TestBreakAndContinueStatements(t *testing.T) {
+	input := `while (true) { break; continue; }`
+
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	stmt := program.Statements[0].(*ast.WhileStatement)
+	if len(stmt.Body.Statements) != 2 {
+		t.Fatalf("body does not contain 2 statements. got=%d", len(stmt.Body.Statements))
+	}
+
+	if _, ok := stmt.Body.Statements[0].(*ast.BreakStatement); !ok {
+		t.Fatalf("first statement is not BreakStatement. got=%T", stmt.Body.Statements[0])
+	}
+	if _, ok := stmt.Body.Statements[1].(*ast.ContinueStatement); !ok {
+		t.Fatalf("second statement is not ContinueStatement. got=%T", stmt.Body.Statements[1])
+	}
+}
+
+func TestMacroLiteralParsing(t *testing.T) {
+	input := `macro(x, y) { x + y; }`
+
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	exprStmt := program.Statements[0].(*ast.ExpressionStatement)
+	macro, ok := exprStmt.Expression.(*ast.MacroLiteral)
+	if !ok {
+		t.Fatalf("expression is not MacroLiteral. got=%T", exprStmt.Expression)
+	}
+
+	if len(macro.Parameters) != 2 {
+		t.Fatalf("wrong number of parameters. got=%d", len(macro.Parameters))
+	}
+	if macro.Parameters[0].Value != "x" || macro.Parameters[1].Value != "y" {
+		t.Fatalf("wrong parameters. got=%+v", macro.Parameters)
+	}
+
+	if len(macro.Body.Statements) != 1 {
+		t.Fatalf("body does not contain 1 statement. got=%d", len(macro.Body.Statements))
+	}
+}
+
+func TestQuoteExpressionParsing(t *testing.T) {
+	input := `quote(1 + unquote(x));`
+
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	exprStmt := program.Statements[0].(*ast.ExpressionStatement)
+	quote, ok := exprStmt.Expression.(*ast.QuoteExpression)
+	if !ok {
+		t.Fatalf("expression is not QuoteExpression. got=%T", exprStmt.Expression)
+	}
+
+	binExpr, ok := quote.Node.(*ast.BinaryExpression)
+	if !ok {
+		t.Fatalf("quoted node is not BinaryExpression. got=%T", quote.Node)
+	}
+
+	call, ok := binExpr.Right.(*ast.CallExpression)
+	if !ok {
+		t.Fatalf("right operand is not CallExpression. got=%T", binExpr.Right)
+	}
+	if ident, ok := call.Function.(*ast.Identifier); !ok || ident.Value != "unquote" {
+		t.Fatalf("call is not to unquote. got=%+v", call.Function)
+	}
+}
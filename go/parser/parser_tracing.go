@@ -0,0 +1,73 @@
+package parser
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// Mode is a bitmask of optional behaviors a Parser can be constructed with,
+// via NewWithMode. Room is left for future flags alongside Trace (e.g. a
+// StatementsOnly mode that skips expression parsing for a quick syntax
+// check).
+type Mode int
+
+const (
+	// Trace makes the parser print an indented BEGIN/END line to TraceOut
+	// for every parseXxx production it enters and leaves, so callers can
+	// watch how an expression descends through Pratt precedence.
+	Trace Mode = 1 << iota
+
+	// ParseComments makes the parser collect comments into CommentGroups and
+	// attach them to the AST instead of silently discarding them: leading
+	// groups become Doc on the declaration/function literal they precede,
+	// trailing same-line groups become Comment on the ExpressionStatement
+	// they follow, and anything left over ends up on Program.Comments.
+	ParseComments
+)
+
+// traceOut returns where trace output is written, defaulting to os.Stdout
+// when TraceOut hasn't been set.
+func (p *Parser) traceOut() io.Writer {
+	if p.TraceOut != nil {
+		return p.TraceOut
+	}
+	return os.Stdout
+}
+
+// identLevel renders the current indent as tabs, prefixed with label (
+// "BEGIN" or "END"), so nested productions visibly nest in the output.
+func (p *Parser) identLevel(label string) string {
+	return strings.Repeat("\t", p.traceIndent-1) + label + " "
+}
+
+func (p *Parser) tracePrint(s string) {
+	fmt.Fprintf(
+		p.traceOut(),
+		"%s (cur: %s %q at %v)\n",
+		s, p.curToken.Type, p.curToken.Literal, p.curToken.Pos,
+	)
+}
+
+// trace is called as `defer p.untrace(p.trace("parseXxx"))` at the top of a
+// parseXxx method. It's a no-op unless the parser was built with Trace.
+func (p *Parser) trace(msg string) string {
+	if p.Mode&Trace == 0 {
+		return msg
+	}
+
+	p.traceIndent++
+	p.tracePrint(p.identLevel("BEGIN") + msg)
+
+	return msg
+}
+
+func (p *Parser) untrace(msg string) {
+	if p.Mode&Trace == 0 {
+		return
+	}
+
+	p.tracePrint(p.identLevel("END") + msg)
+	p.traceIndent--
+}
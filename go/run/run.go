@@ -3,15 +3,28 @@ package run
 import (
 	"bufio"
 	"fmt"
+	"gorb/ast"
+	"gorb/compiler"
 	"gorb/evaluator"
 	"gorb/lexer"
 	"gorb/object"
 	"gorb/parser"
+	"gorb/token"
+	"gorb/typechecker"
+	"gorb/vm"
 	"io"
 	"os"
+	"strings"
 )
 
-func ExecuteFile(out io.Writer, path string) *object.Environment {
+func init() {
+	// Wire parser.ExpandMacros to the tree-walking evaluator. parser can't
+	// import gorb/evaluator itself without an import cycle through
+	// evaluator's own tests, which import parser to build test input.
+	parser.Eval = evaluator.Eval
+}
+
+func ExecuteFile(out io.Writer, path string, typeCheck bool) *object.Environment {
 	data, err := os.ReadFile(path)
 
 	if err != nil {
@@ -23,18 +36,73 @@ func ExecuteFile(out io.Writer, path string) *object.Environment {
 	text := string(data)
 
 	env := object.NewEnvironment()
-	val := Run(text, env, out)
+	val := Run(text, env, out, typeCheck)
 	fmt.Println()
 
 	if val != nil && val.Type() == object.ERROR {
-		io.WriteString(out, val.Inspect())
-		io.WriteString(out, "\n")
 		return nil
 	}
 
 	return env
 }
 
+// ExecuteFileVM is ExecuteFile's counterpart for the bytecode backend: it
+// compiles path and runs it on the vm instead of the tree-walking
+// evaluator. It has no environment to hand back for an interactive
+// session to continue in, since the vm keeps its state as a globals slice
+// rather than an *object.Environment, so it just reports success.
+func ExecuteFileVM(out io.Writer, path string, typeCheck bool) bool {
+	data, err := os.ReadFile(path)
+
+	if err != nil {
+		fmt.Println("can't read file:", path)
+		fmt.Println(err.Error())
+		return false
+	}
+
+	text := string(data)
+
+	val := RunVM(text, out, typeCheck)
+	fmt.Println()
+
+	return val != nil && val.Type() != object.ERROR
+}
+
+// RunVM lexes, parses, and compiles text, then runs the resulting bytecode
+// on the vm. It mirrors Run's error reporting so -vm output looks the same
+// as the tree-walking backend's.
+func RunVM(text string, out io.Writer, typeCheck bool) object.Object {
+	l := lexer.New(text)
+	p := parser.New(l)
+
+	program := p.ParseProgram()
+	if len(p.Errors()) != 0 {
+		printParserErrors(out, p.Errors())
+		return nil
+	}
+
+	if typeCheck {
+		if errs := typechecker.Check(program); len(errs) != 0 {
+			printTypeErrors(out, text, errs)
+			return nil
+		}
+	}
+
+	comp := compiler.New()
+	if err := comp.Compile(program); err != nil {
+		io.WriteString(out, "compile error: "+err.Error()+"\n")
+		return &object.Error{Message: err.Error()}
+	}
+
+	machine := vm.New(comp.Bytecode())
+	if err := machine.Run(); err != nil {
+		io.WriteString(out, "runtime error: "+err.Error()+"\n")
+		return &object.Error{Message: err.Error()}
+	}
+
+	return machine.LastPoppedStackElem()
+}
+
 const PROMPT = ">> "
 
 func StartRepl(in io.Reader, out io.Writer, env *object.Environment) {
@@ -53,16 +121,20 @@ func StartRepl(in io.Reader, out io.Writer, env *object.Environment) {
 		}
 
 		line := scanner.Text()
-		val := Run(line, env, out)
+		val := Run(line, env, out, false)
 
-		if val != nil {
+		if val != nil && val.Type() != object.ERROR {
 			io.WriteString(out, val.Inspect())
 			io.WriteString(out, "\n")
 		}
 	}
 }
 
-func Run(text string, env *object.Environment, out io.Writer) object.Object {
+// Run lexes, parses, and evaluates text against env. When typeCheck is set,
+// the program is rejected with type errors instead of being evaluated; the
+// REPL runs with typeCheck off so exploratory snippets aren't blocked by a
+// checker that can't see the rest of the session.
+func Run(text string, env *object.Environment, out io.Writer, typeCheck bool) object.Object {
 	l := lexer.New(text)
 	p := parser.New(l)
 
@@ -71,15 +143,90 @@ func Run(text string, env *object.Environment, out io.Writer) object.Object {
 		printParserErrors(out, p.Errors())
 		return nil
 	}
+
+	if typeCheck {
+		if errs := typechecker.Check(program); len(errs) != 0 {
+			printTypeErrors(out, text, errs)
+			return nil
+		}
+	}
 	// io.WriteString(out, program.String())
 	// io.WriteString(out, "\n")
 
-	return evaluator.Eval(program, env)
+	expanded, err := expandMacros(program)
+	if err != nil {
+		io.WriteString(out, "macro error: "+err.Message+"\n")
+		return err
+	}
+
+	val := evaluator.Eval(expanded, env)
+	if err, ok := val.(*object.Error); ok {
+		printRuntimeError(out, text, err)
+	}
+
+	return val
+}
+
+// expandMacros runs parser.DefineMacros and parser.ExpandMacros against
+// program, recovering from the panic that macro misuse (e.g. a macro
+// called with the wrong number of arguments, or one that doesn't return a
+// quoted AST node) raises deep inside ast.Modify, and reporting it the
+// same way any other pre-evaluation failure is reported.
+func expandMacros(program *ast.Program) (node ast.Node, err *object.Error) {
+	defer func() {
+		if r := recover(); r != nil {
+			node, err = nil, &object.Error{Message: fmt.Sprintf("%v", r)}
+		}
+	}()
+
+	macroEnv := object.NewEnvironment()
+	parser.DefineMacros(program, macroEnv)
+	return parser.ExpandMacros(program, macroEnv), nil
 }
 
-func printParserErrors(out io.Writer, errors []string) {
+func printParserErrors(out io.Writer, errors token.ErrorList) {
 	io.WriteString(out, "syntax error!\n")
-	for _, msg := range errors {
-		io.WriteString(out, "\t"+msg+"\n")
+	for _, err := range errors {
+		io.WriteString(out, "\t"+err.Error()+"\n")
 	}
-}
\ No newline at end of file
+}
+
+func printTypeErrors(out io.Writer, source string, errors []*typechecker.Error) {
+	io.WriteString(out, "type error!\n")
+	for _, err := range errors {
+		io.WriteString(out, "\t"+err.Msg+"\n")
+		printSnippet(out, source, err.Pos)
+	}
+}
+
+func printRuntimeError(out io.Writer, source string, err *object.Error) {
+	io.WriteString(out, "runtime error: "+err.Message+"\n")
+	printSnippet(out, source, err.Pos)
+
+	for i := len(err.Trace) - 1; i >= 0; i-- {
+		io.WriteString(out, "\tin call from:\n")
+		printSnippet(out, source, err.Trace[i])
+	}
+}
+
+// printSnippet renders the source line pos points at, with a caret
+// underneath the offending column, e.g.:
+//
+//	3 | let x = foo + 1
+//	  |         ^ identifier not found: foo
+func printSnippet(out io.Writer, source string, pos token.Pos) {
+	lines := strings.Split(source, "\n")
+	if pos.Ln < 1 || pos.Ln > len(lines) {
+		return
+	}
+
+	gutter := fmt.Sprintf("%4d | ", pos.Ln)
+	io.WriteString(out, gutter+lines[pos.Ln-1]+"\n")
+
+	col := pos.Col
+	if col < 1 {
+		col = 1
+	}
+	padding := strings.Repeat(" ", len(gutter)-2) + "| " + strings.Repeat(" ", col-1)
+	io.WriteString(out, padding+"^\n")
+}
@@ -0,0 +1,68 @@
+package token
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Error is a single error tied to the source position it was found at,
+// mirroring go/scanner.Error.
+type Error struct {
+	Pos Pos
+	Msg string
+}
+
+func (e *Error) Error() string { return fmt.Sprintf("%v %s", e.Pos, e.Msg) }
+
+// ErrorList collects the Errors a lex or parse run produced, the way
+// go/scanner.ErrorList does, so callers can inspect, sort, or count them
+// instead of matching against formatted strings.
+type ErrorList []*Error
+
+func (l ErrorList) Len() int { return len(l) }
+
+// Sort orders the list by position, the order a reader would hit them in
+// the source file.
+func (l ErrorList) Sort() {
+	sort.Slice(l, func(i, j int) bool {
+		if l[i].Pos.Ln != l[j].Pos.Ln {
+			return l[i].Pos.Ln < l[j].Pos.Ln
+		}
+		return l[i].Pos.Col < l[j].Pos.Col
+	})
+}
+
+func (l ErrorList) String() string {
+	msgs := make([]string, len(l))
+	for i, e := range l {
+		msgs[i] = e.Error()
+	}
+	return strings.Join(msgs, "\n")
+}
+
+// Err returns the list as an error, or nil if it's empty, so a caller that
+// doesn't care about individual errors can treat it like any other error
+// return value.
+func (l ErrorList) Err() error {
+	if len(l) == 0 {
+		return nil
+	}
+	return fmt.Errorf("%s", l.String())
+}
+
+// ErrorHandler is the callback a Lexer or Parser reports errors to as it
+// encounters them, instead of appending to a slice of formatted strings.
+type ErrorHandler interface {
+	Error(pos Pos, msg string)
+}
+
+// CollectingHandler is the default ErrorHandler: it appends every error it's
+// given to Errors, in the order they're reported.
+type CollectingHandler struct {
+	Errors ErrorList
+}
+
+func (h *CollectingHandler) Error(pos Pos, msg string) {
+	h.Errors = append(h.Errors, &Error{Pos: pos, Msg: msg})
+}
@@ -0,0 +1,41 @@
+package token
+
+import "testing"
+
+func TestErrorListSortOrdersByPosition(t *testing.T) {
+	list := ErrorList{
+		{Pos: Pos{Ln: 2, Col: 3}, Msg: "second line"},
+		{Pos: Pos{Ln: 1, Col: 5}, Msg: "first line, later col"},
+		{Pos: Pos{Ln: 1, Col: 1}, Msg: "first line, first col"},
+	}
+
+	list.Sort()
+
+	if list[0].Msg != "first line, first col" || list[1].Msg != "first line, later col" || list[2].Msg != "second line" {
+		t.Fatalf("list not sorted by position: %v", list)
+	}
+}
+
+func TestErrorListErrIsNilWhenEmpty(t *testing.T) {
+	var list ErrorList
+	if err := list.Err(); err != nil {
+		t.Fatalf("expected nil error for empty list, got %v", err)
+	}
+}
+
+func TestErrorListErrIsNonNilWhenNotEmpty(t *testing.T) {
+	list := ErrorList{{Pos: Pos{Ln: 1, Col: 1}, Msg: "boom"}}
+	if err := list.Err(); err == nil {
+		t.Fatalf("expected a non-nil error for a non-empty list")
+	}
+}
+
+func TestCollectingHandlerAppendsInOrder(t *testing.T) {
+	h := &CollectingHandler{}
+	h.Error(Pos{Ln: 1, Col: 1}, "first")
+	h.Error(Pos{Ln: 2, Col: 1}, "second")
+
+	if len(h.Errors) != 2 || h.Errors[0].Msg != "first" || h.Errors[1].Msg != "second" {
+		t.Fatalf("unexpected errors: %v", h.Errors)
+	}
+}
@@ -35,7 +35,9 @@ const (
 	// identifiers and literals
 	IDENTIFIER = "IDENTIFIER"
 	INTEGER    = "INTEGER"
+	FLOAT      = "FLOAT"
 	STRING     = "STRING"
+	COMMENT    = "COMMENT"
 
 	// operators
 	ASSIGN       = "="
@@ -59,6 +61,7 @@ const (
 	BRACE_CLOSE   = "}"
 	BRACKET_OPEN  = "["
 	BRACKET_CLOSE = "]"
+	DOT           = "."
 
 	//keywords
 	FUNCTION    = "FUNCTION"
@@ -68,16 +71,32 @@ const (
 	IF          = "IF"
 	ELSE        = "ELSE"
 	RETURN      = "RETURN"
+	WHILE       = "WHILE"
+	FOR         = "FOR"
+	BREAK       = "BREAK"
+	CONTINUE    = "CONTINUE"
+	STRUCT      = "STRUCT"
+	MACRO       = "MACRO"
+	QUOTE       = "QUOTE"
+	UNQUOTE     = "UNQUOTE"
 )
 
 var keywords = map[string]TokenType{
-	"fn":     FUNCTION,
-	"let":    DECLARATION,
-	"true":   TRUE,
-	"false":  FALSE,
-	"if":     IF,
-	"else":   ELSE,
-	"return": RETURN,
+	"fn":       FUNCTION,
+	"let":      DECLARATION,
+	"true":     TRUE,
+	"false":    FALSE,
+	"if":       IF,
+	"else":     ELSE,
+	"return":   RETURN,
+	"while":    WHILE,
+	"for":      FOR,
+	"break":    BREAK,
+	"continue": CONTINUE,
+	"struct":   STRUCT,
+	"macro":    MACRO,
+	"quote":    QUOTE,
+	"unquote":  UNQUOTE,
 }
 
 func LookupIdentifier(ident string) TokenType {
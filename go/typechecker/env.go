@@ -0,0 +1,23 @@
+package typechecker
+
+func NewTypeEnv(outer *TypeEnv) *TypeEnv {
+	return &TypeEnv{store: make(map[string]*Type), outer: outer}
+}
+
+type TypeEnv struct {
+	store map[string]*Type
+	outer *TypeEnv
+}
+
+func (e *TypeEnv) Get(name string) (*Type, bool) {
+	t, ok := e.store[name]
+	if !ok && e.outer != nil {
+		t, ok = e.outer.Get(name)
+	}
+	return t, ok
+}
+
+func (e *TypeEnv) Set(name string, t *Type) *Type {
+	e.store[name] = t
+	return t
+}
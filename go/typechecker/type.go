@@ -0,0 +1,93 @@
+package typechecker
+
+import (
+	"fmt"
+	"strings"
+)
+
+type Kind int
+
+const (
+	ANY Kind = iota
+	INT
+	STRING
+	BOOL
+	ARRAY
+	HASH
+	FUNCTION
+	STRUCT
+)
+
+type Type struct {
+	Kind   Kind
+	Elem   *Type   // ARRAY
+	Key    *Type   // HASH
+	Value  *Type   // HASH
+	Params []*Type // FUNCTION
+	Return *Type   // FUNCTION
+
+	Name   string           // STRUCT
+	Fields map[string]*Type // STRUCT
+}
+
+func (t *Type) String() string {
+	if t == nil {
+		return "any"
+	}
+
+	switch t.Kind {
+	case INT:
+		return "int"
+	case STRING:
+		return "string"
+	case BOOL:
+		return "bool"
+	case ARRAY:
+		return fmt.Sprintf("array<%s>", t.Elem.String())
+	case HASH:
+		return fmt.Sprintf("hash<%s, %s>", t.Key.String(), t.Value.String())
+	case FUNCTION:
+		params := make([]string, len(t.Params))
+		for i, p := range t.Params {
+			params[i] = p.String()
+		}
+		return fmt.Sprintf("fn(%s) -> %s", strings.Join(params, ", "), t.Return.String())
+	case STRUCT:
+		return t.Name
+	default:
+		return "any"
+	}
+}
+
+// compatible reports whether a value of type got can be used where want is
+// expected. ANY is compatible with everything in both directions.
+func compatible(want, got *Type) bool {
+	if want == nil || got == nil || want.Kind == ANY || got.Kind == ANY {
+		return true
+	}
+
+	if want.Kind != got.Kind {
+		return false
+	}
+
+	switch want.Kind {
+	case ARRAY:
+		return compatible(want.Elem, got.Elem)
+	case HASH:
+		return compatible(want.Key, got.Key) && compatible(want.Value, got.Value)
+	case FUNCTION:
+		if len(want.Params) != len(got.Params) {
+			return false
+		}
+		for i := range want.Params {
+			if !compatible(want.Params[i], got.Params[i]) {
+				return false
+			}
+		}
+		return compatible(want.Return, got.Return)
+	case STRUCT:
+		return want.Name == got.Name
+	default:
+		return true
+	}
+}
@@ -0,0 +1,428 @@
+package typechecker
+
+import (
+	"fmt"
+	"gorb/ast"
+	"gorb/token"
+)
+
+type Error struct {
+	Pos token.Pos
+	Msg string
+}
+
+func (e *Error) Error() string { return fmt.Sprintf("%v %s", e.Pos, e.Msg) }
+
+type Checker struct {
+	errors      []*Error
+	returnStack []*Type
+}
+
+// Check walks program and reports every type error it finds. It does not
+// stop at the first one, so callers can surface the whole batch at once.
+func Check(program *ast.Program) []*Error {
+	c := &Checker{}
+	env := NewTypeEnv(nil)
+
+	for _, stmt := range program.Statements {
+		c.checkStatement(stmt, env)
+	}
+
+	return c.errors
+}
+
+func (c *Checker) errorf(pos token.Pos, format string, a ...interface{}) {
+	c.errors = append(c.errors, &Error{Pos: pos, Msg: fmt.Sprintf(format, a...)})
+}
+
+func (c *Checker) checkStatement(stmt ast.Statement, env *TypeEnv) {
+	switch stmt := stmt.(type) {
+	case *ast.DeclarationStatement:
+		c.checkDeclarationStatement(stmt, env)
+
+	case *ast.AssignStatement:
+		c.checkAssignStatement(stmt, env)
+
+	case *ast.ReturnStatement:
+		got := c.infer(stmt.ReturnValue, env)
+		if len(c.returnStack) > 0 {
+			want := c.returnStack[len(c.returnStack)-1]
+			if !compatible(want, got) {
+				c.errorf(
+					stmt.Token.Pos,
+					"cannot return %s, function declared to return %s",
+					got, want,
+				)
+			}
+		}
+
+	case *ast.ExpressionStatement:
+		c.infer(stmt.Expression, env)
+
+	case *ast.BlockStatement:
+		// No enclosed TypeEnv here: the evaluator's evalBlockStatement
+		// never creates an enclosed object.Environment either (only
+		// function calls do, in extendFunctionEnv), so a block is flat
+		// with its surrounding scope at runtime. Checking it in a child
+		// TypeEnv would let a `let` that shadows an outer name pass as a
+		// "new" binding here while it actually overwrites the outer one
+		// when evaluated, which is unsound.
+		for _, s := range stmt.Statements {
+			c.checkStatement(s, env)
+		}
+
+	case *ast.WhileStatement:
+		c.infer(stmt.Condition, env)
+		c.checkStatement(stmt.Body, env)
+
+	case *ast.ForStatement:
+		// Flat, like BlockStatement above: evalForStatement evaluates
+		// Init/Condition/Post/Body all against the same env, with no
+		// enclosed scope for the loop variable.
+		if stmt.Init != nil {
+			c.checkStatement(stmt.Init, env)
+		}
+		if stmt.Condition != nil {
+			c.infer(stmt.Condition, env)
+		}
+		if stmt.Post != nil {
+			c.checkStatement(stmt.Post, env)
+		}
+		c.checkStatement(stmt.Body, env)
+
+	case *ast.StructDeclaration:
+		c.checkStructDeclaration(stmt, env)
+	}
+}
+
+func (c *Checker) checkStructDeclaration(stmt *ast.StructDeclaration, env *TypeEnv) {
+	fields := make(map[string]*Type, len(stmt.Fields))
+	for i, f := range stmt.Fields {
+		var fieldType *Type
+		if i < len(stmt.FieldTypes) {
+			fieldType = fromAnnotation(stmt.FieldTypes[i])
+		}
+		fields[f.Value] = fieldType
+	}
+
+	env.Set(stmt.Name.Value, &Type{Kind: STRUCT, Name: stmt.Name.Value, Fields: fields})
+}
+
+func (c *Checker) checkDeclarationStatement(stmt *ast.DeclarationStatement, env *TypeEnv) {
+	// Bind the name to its signature before checking the value, so a
+	// function literal can call itself recursively and still have its
+	// arity and argument types checked.
+	if fl, ok := stmt.Value.(*ast.FunctionLiteral); ok {
+		env.Set(stmt.Name.Value, functionSignature(fl))
+	}
+
+	got := c.infer(stmt.Value, env)
+
+	if stmt.TypeAnnotation == nil {
+		env.Set(stmt.Name.Value, got)
+		return
+	}
+
+	want := fromAnnotation(stmt.TypeAnnotation)
+	if !compatible(want, got) {
+		c.errorf(
+			stmt.Token.Pos,
+			"%s annotated as %s but assigned %s",
+			stmt.Name.Value, want, got,
+		)
+	}
+
+	env.Set(stmt.Name.Value, want)
+}
+
+// checkAssignStatement checks a reassignment of an already-bound name,
+// reporting an error if the name was never declared with let or if the new
+// value's type doesn't match the binding's existing type.
+func (c *Checker) checkAssignStatement(stmt *ast.AssignStatement, env *TypeEnv) {
+	want, ok := env.Get(stmt.Name.Value)
+	if !ok {
+		c.errorf(stmt.Token.Pos, "identifier not found: %s", stmt.Name.Value)
+		return
+	}
+
+	got := c.infer(stmt.Value, env)
+	if !compatible(want, got) {
+		c.errorf(stmt.Token.Pos, "%s is %s but assigned %s", stmt.Name.Value, want, got)
+	}
+}
+
+func (c *Checker) infer(exp ast.Expression, env *TypeEnv) *Type {
+	switch exp := exp.(type) {
+	case *ast.IntegerLiteral:
+		return &Type{Kind: INT}
+
+	case *ast.StringLiteral:
+		return &Type{Kind: STRING}
+
+	case *ast.BooleanLiteral:
+		return &Type{Kind: BOOL}
+
+	case *ast.Identifier:
+		if t, ok := env.Get(exp.Value); ok {
+			return t
+		}
+		return &Type{Kind: ANY}
+
+	case *ast.ArrayLiteral:
+		elem := &Type{Kind: ANY}
+		for i, el := range exp.Elements {
+			t := c.infer(el, env)
+			if i == 0 {
+				elem = t
+			} else if !compatible(elem, t) {
+				elem = &Type{Kind: ANY}
+			}
+		}
+		return &Type{Kind: ARRAY, Elem: elem}
+
+	case *ast.HashLiteral:
+		key := &Type{Kind: ANY}
+		val := &Type{Kind: ANY}
+		first := true
+		for k, v := range exp.Pairs {
+			kt := c.infer(k, env)
+			vt := c.infer(v, env)
+			if first {
+				key, val = kt, vt
+				first = false
+				continue
+			}
+			if !compatible(key, kt) {
+				key = &Type{Kind: ANY}
+			}
+			if !compatible(val, vt) {
+				val = &Type{Kind: ANY}
+			}
+		}
+		return &Type{Kind: HASH, Key: key, Value: val}
+
+	case *ast.UnaryExpression:
+		return c.inferUnaryExpression(exp, env)
+
+	case *ast.BinaryExpression:
+		return c.inferBinaryExpression(exp, env)
+
+	case *ast.IfExpression:
+		c.infer(exp.Condition, env)
+		c.checkStatement(exp.Consequence, env)
+		if exp.Alternative != nil {
+			c.checkStatement(exp.Alternative, env)
+		}
+		return &Type{Kind: ANY}
+
+	case *ast.FunctionLiteral:
+		return c.inferFunctionLiteral(exp, env)
+
+	case *ast.CallExpression:
+		return c.inferCallExpression(exp, env)
+
+	case *ast.IndexExpression:
+		left := c.infer(exp.Left, env)
+		c.infer(exp.Index, env)
+		if left.Kind == ARRAY {
+			return left.Elem
+		}
+		if left.Kind == HASH {
+			return left.Value
+		}
+		return &Type{Kind: ANY}
+
+	case *ast.StructLiteral:
+		return c.inferStructLiteral(exp, env)
+
+	case *ast.FieldAccessExpression:
+		left := c.infer(exp.Left, env)
+		if left.Kind != STRUCT {
+			return &Type{Kind: ANY}
+		}
+		fieldType, ok := left.Fields[exp.Field.Value]
+		if !ok {
+			c.errorf(exp.Pos(), "undefined field %q on struct %s", exp.Field.Value, left.Name)
+			return &Type{Kind: ANY}
+		}
+		return fieldType
+
+	default:
+		return &Type{Kind: ANY}
+	}
+}
+
+func (c *Checker) inferUnaryExpression(exp *ast.UnaryExpression, env *TypeEnv) *Type {
+	right := c.infer(exp.Right, env)
+
+	switch exp.Operator {
+	case token.MINUS:
+		if right.Kind != ANY && right.Kind != INT {
+			c.errorf(exp.Token.Pos, "unknown operation: -%s", right)
+		}
+		return &Type{Kind: INT}
+	case token.BANG:
+		return &Type{Kind: BOOL}
+	default:
+		return &Type{Kind: ANY}
+	}
+}
+
+func (c *Checker) inferBinaryExpression(exp *ast.BinaryExpression, env *TypeEnv) *Type {
+	left := c.infer(exp.Left, env)
+	right := c.infer(exp.Right, env)
+
+	switch exp.Operator {
+	case token.EQUALS, token.NOT_EQUALS:
+		return &Type{Kind: BOOL}
+
+	case token.LESS_THAN, token.GREATER_THAN:
+		if !numeric(left) || !numeric(right) {
+			c.errorf(exp.Token.Pos, "unknown operation: %s %s %s", left, exp.Operator, right)
+		}
+		return &Type{Kind: BOOL}
+
+	case token.PLUS:
+		if left.Kind == STRING || right.Kind == STRING {
+			if left.Kind != ANY && left.Kind != STRING {
+				c.errorf(exp.Token.Pos, "type mismatch: %s %s %s", left, exp.Operator, right)
+			}
+			if right.Kind != ANY && right.Kind != STRING {
+				c.errorf(exp.Token.Pos, "type mismatch: %s %s %s", left, exp.Operator, right)
+			}
+			return &Type{Kind: STRING}
+		}
+		fallthrough
+
+	case token.MINUS, token.ASTERISK, token.SLASH:
+		if !numeric(left) || !numeric(right) {
+			c.errorf(exp.Token.Pos, "unknown operation: %s %s %s", left, exp.Operator, right)
+		}
+		return &Type{Kind: INT}
+
+	default:
+		return &Type{Kind: ANY}
+	}
+}
+
+func numeric(t *Type) bool {
+	return t.Kind == ANY || t.Kind == INT
+}
+
+// functionSignature computes a function literal's type from its parameter
+// and return annotations alone, without checking its body. Used to bind a
+// declared function's name to its own signature before its body is
+// checked, so recursive calls are checked like any other call.
+func functionSignature(fl *ast.FunctionLiteral) *Type {
+	params := make([]*Type, len(fl.Parameters))
+	for i := range fl.Parameters {
+		t := &Type{Kind: ANY}
+		if i < len(fl.ParamTypes) && fl.ParamTypes[i] != nil {
+			t = fromAnnotation(fl.ParamTypes[i])
+		}
+		params[i] = t
+	}
+
+	return &Type{Kind: FUNCTION, Params: params, Return: fromAnnotation(fl.ReturnType)}
+}
+
+func (c *Checker) inferFunctionLiteral(fl *ast.FunctionLiteral, env *TypeEnv) *Type {
+	sig := functionSignature(fl)
+	fnEnv := NewTypeEnv(env)
+
+	for i, param := range fl.Parameters {
+		fnEnv.Set(param.Value, sig.Params[i])
+	}
+
+	c.returnStack = append(c.returnStack, sig.Return)
+	c.checkStatement(fl.Body, fnEnv)
+	c.returnStack = c.returnStack[:len(c.returnStack)-1]
+
+	return sig
+}
+
+func (c *Checker) inferCallExpression(exp *ast.CallExpression, env *TypeEnv) *Type {
+	fnType := c.infer(exp.Function, env)
+
+	args := make([]*Type, len(exp.Arguments))
+	for i, a := range exp.Arguments {
+		args[i] = c.infer(a, env)
+	}
+
+	if fnType.Kind != FUNCTION {
+		return &Type{Kind: ANY}
+	}
+
+	if len(args) != len(fnType.Params) {
+		c.errorf(
+			exp.Token.Pos,
+			"wrong number of arguments: got=%d, want=%d",
+			len(args), len(fnType.Params),
+		)
+		return fnType.Return
+	}
+
+	for i, want := range fnType.Params {
+		if !compatible(want, args[i]) {
+			c.errorf(
+				exp.Token.Pos,
+				"argument %d: expected %s, got %s",
+				i+1, want, args[i],
+			)
+		}
+	}
+
+	return fnType.Return
+}
+
+// inferStructLiteral resolves node.Name to its declared struct type and
+// checks the literal's fields against it: every declared field must be
+// present with a compatible value, and no undeclared fields are allowed.
+func (c *Checker) inferStructLiteral(node *ast.StructLiteral, env *TypeEnv) *Type {
+	declType, ok := env.Get(node.Name.Value)
+	if !ok || declType.Kind != STRUCT {
+		c.errorf(node.Pos(), "struct not found: %s", node.Name.Value)
+		return &Type{Kind: ANY}
+	}
+
+	for name := range node.Fields {
+		if _, ok := declType.Fields[name]; !ok {
+			c.errorf(node.Pos(), "unknown field %q for struct %s", name, declType.Name)
+		}
+	}
+
+	for name, want := range declType.Fields {
+		valueNode, ok := node.Fields[name]
+		if !ok {
+			c.errorf(node.Pos(), "missing field %q for struct %s", name, declType.Name)
+			continue
+		}
+		got := c.infer(valueNode, env)
+		if !compatible(want, got) {
+			c.errorf(node.Pos(), "field %q of %s: expected %s, got %s", name, declType.Name, want, got)
+		}
+	}
+
+	return declType
+}
+
+func fromAnnotation(ann *ast.TypeAnnotation) *Type {
+	if ann == nil {
+		return &Type{Kind: ANY}
+	}
+
+	switch ann.Name {
+	case "int":
+		return &Type{Kind: INT}
+	case "string":
+		return &Type{Kind: STRING}
+	case "bool":
+		return &Type{Kind: BOOL}
+	case "array":
+		return &Type{Kind: ARRAY, Elem: &Type{Kind: ANY}}
+	case "hash":
+		return &Type{Kind: HASH, Key: &Type{Kind: ANY}, Value: &Type{Kind: ANY}}
+	default:
+		return &Type{Kind: ANY}
+	}
+}
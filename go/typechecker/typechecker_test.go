@@ -0,0 +1,111 @@
+package typechecker
+
+import (
+	"gorb/lexer"
+	"gorb/parser"
+	"testing"
+)
+
+func checkInput(t *testing.T, input string) []*Error {
+	t.Helper()
+
+	l := lexer.New(input)
+	p := parser.New(l)
+	program := p.ParseProgram()
+
+	if errs := p.Errors(); len(errs) != 0 {
+		t.Fatalf("parser errors: %v", errs)
+	}
+
+	return Check(program)
+}
+
+func TestRecursiveCallIsChecked(t *testing.T) {
+	input := `
+	let fib = fn(n: int): int {
+		if (n < 2) {
+			return n;
+		}
+		return fib(n - 1, 99);
+	};
+	`
+
+	errs := checkInput(t, input)
+	if len(errs) == 0 {
+		t.Fatalf("expected a type error for the wrong-arity recursive call, got none")
+	}
+}
+
+func TestRecursiveCallWithCorrectArity(t *testing.T) {
+	input := `
+	let fib = fn(n: int): int {
+		if (n < 2) {
+			return n;
+		}
+		return fib(n - 1);
+	};
+	`
+
+	errs := checkInput(t, input)
+	if len(errs) != 0 {
+		t.Fatalf("expected no type errors, got %v", errs)
+	}
+}
+
+func TestStructLiteralFieldTypeMismatchIsChecked(t *testing.T) {
+	input := `
+	struct Point { x: int, y: int }
+	let p = Point{ x: 1, y: "oops" };
+	`
+
+	errs := checkInput(t, input)
+	if len(errs) == 0 {
+		t.Fatalf("expected a type error for the mismatched field, got none")
+	}
+}
+
+func TestStructLiteralWithCorrectFieldsIsChecked(t *testing.T) {
+	input := `
+	struct Point { x: int, y: int }
+	let p = Point{ x: 1, y: 2 };
+	`
+
+	errs := checkInput(t, input)
+	if len(errs) != 0 {
+		t.Fatalf("expected no type errors, got %v", errs)
+	}
+}
+
+func TestAssignStatementTypeMismatchIsChecked(t *testing.T) {
+	input := `
+	let x = 1;
+	x = "oops";
+	`
+
+	errs := checkInput(t, input)
+	if len(errs) == 0 {
+		t.Fatalf("expected a type error for the mismatched assignment, got none")
+	}
+}
+
+func TestAssignStatementToUnboundNameIsChecked(t *testing.T) {
+	input := `x = 1;`
+
+	errs := checkInput(t, input)
+	if len(errs) == 0 {
+		t.Fatalf("expected a type error for assigning to an unbound name, got none")
+	}
+}
+
+func TestFieldAccessOnUnknownFieldIsChecked(t *testing.T) {
+	input := `
+	struct Point { x: int, y: int }
+	let p = Point{ x: 1, y: 2 };
+	p.z;
+	`
+
+	errs := checkInput(t, input)
+	if len(errs) == 0 {
+		t.Fatalf("expected a type error for the unknown field, got none")
+	}
+}
@@ -0,0 +1,42 @@
+package vm
+
+import (
+	"gorb/compiler"
+	"gorb/evaluator"
+	"gorb/object"
+	"testing"
+)
+
+const fibInput = `
+let fib = fn(n) {
+	if (n < 2) { return n; }
+	return fib(n - 1) + fib(n - 2);
+};
+fib(20);
+`
+
+func BenchmarkFibEvaluator(b *testing.B) {
+	program := parse(fibInput)
+
+	for i := 0; i < b.N; i++ {
+		env := object.NewEnvironment()
+		evaluator.Eval(program, env)
+	}
+}
+
+func BenchmarkFibVM(b *testing.B) {
+	program := parse(fibInput)
+
+	comp := compiler.New()
+	if err := comp.Compile(program); err != nil {
+		b.Fatalf("compiler error: %s", err)
+	}
+	bytecode := comp.Bytecode()
+
+	for i := 0; i < b.N; i++ {
+		machine := New(bytecode)
+		if err := machine.Run(); err != nil {
+			b.Fatalf("vm error: %s", err)
+		}
+	}
+}
@@ -0,0 +1,23 @@
+package vm
+
+import (
+	"gorb/code"
+	"gorb/object"
+)
+
+// Frame is one call's worth of execution state: the closure being run, the
+// instruction pointer into its instructions, and where its locals begin on
+// the vm's shared stack.
+type Frame struct {
+	cl          *object.Closure
+	ip          int
+	basePointer int
+}
+
+func NewFrame(cl *object.Closure, basePointer int) *Frame {
+	return &Frame{cl: cl, ip: -1, basePointer: basePointer}
+}
+
+func (f *Frame) Instructions() code.Instructions {
+	return f.cl.Fn.Instructions
+}
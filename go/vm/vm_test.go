@@ -0,0 +1,202 @@
+package vm
+
+import (
+	"fmt"
+	"gorb/ast"
+	"gorb/compiler"
+	"gorb/lexer"
+	"gorb/object"
+	"gorb/parser"
+	"testing"
+)
+
+type vmTestCase struct {
+	input    string
+	expected interface{}
+}
+
+func TestIntegerArithmetic(t *testing.T) {
+	tests := []vmTestCase{
+		{"1", 1},
+		{"2", 2},
+		{"1 + 2", 3},
+		{"1 - 2", -1},
+		{"2 * 2", 4},
+		{"4 / 2", 2},
+		{"(1 + 2) * 3", 9},
+		{"-5", -5},
+	}
+
+	runVmTests(t, tests)
+}
+
+func TestBooleanExpressions(t *testing.T) {
+	tests := []vmTestCase{
+		{"true", true},
+		{"false", false},
+		{"1 < 2", true},
+		{"1 > 2", false},
+		{"1 == 1", true},
+		{"1 != 1", false},
+		{"!true", false},
+		{"!!true", true},
+	}
+
+	runVmTests(t, tests)
+}
+
+func TestConditionals(t *testing.T) {
+	tests := []vmTestCase{
+		{"if (true) { 10 }", 10},
+		{"if (false) { 10 } else { 20 }", 20},
+		{"if (false) { 10 }", Null},
+	}
+
+	runVmTests(t, tests)
+}
+
+func TestGlobalDeclarationStatements(t *testing.T) {
+	tests := []vmTestCase{
+		{"let one = 1; one", 1},
+		{"let one = 1; let two = one + one; one + two", 3},
+	}
+
+	runVmTests(t, tests)
+}
+
+func TestStringExpressions(t *testing.T) {
+	tests := []vmTestCase{
+		{`"gorb"`, "gorb"},
+		{`"go" + "rb"`, "gorb"},
+	}
+
+	runVmTests(t, tests)
+}
+
+func TestArrayLiterals(t *testing.T) {
+	tests := []vmTestCase{
+		{"[1, 2, 3][1]", 2},
+		{"[1, 2, 3][99]", Null},
+	}
+
+	runVmTests(t, tests)
+}
+
+func TestCallingFunctions(t *testing.T) {
+	tests := []vmTestCase{
+		{"let fivePlusTen = fn() { 5 + 10; }; fivePlusTen();", 15},
+		{"let one = fn() { 1; }; let two = fn() { 2; }; one() + two()", 3},
+		{"let identity = fn(a) { a; }; identity(4);", 4},
+		{
+			`
+			let fib = fn(n) {
+				if (n < 2) { return n; }
+				return fib(n - 1) + fib(n - 2);
+			};
+			fib(10);
+			`,
+			55,
+		},
+	}
+
+	runVmTests(t, tests)
+}
+
+func TestClosures(t *testing.T) {
+	tests := []vmTestCase{
+		{
+			`
+			let newAdder = fn(a) {
+				fn(b) { a + b; };
+			};
+			let addTwo = newAdder(2);
+			addTwo(3);
+			`,
+			5,
+		},
+	}
+
+	runVmTests(t, tests)
+}
+
+func TestBuiltinFunctions(t *testing.T) {
+	tests := []vmTestCase{
+		{`len("")`, 0},
+		{`len("four")`, 4},
+		{`len([1, 2, 3])`, 3},
+		{`first([1, 2, 3])`, 1},
+		{`last([1, 2, 3])`, 3},
+	}
+
+	runVmTests(t, tests)
+}
+
+func runVmTests(t *testing.T, tests []vmTestCase) {
+	t.Helper()
+
+	for _, tt := range tests {
+		program := parse(tt.input)
+
+		comp := compiler.New()
+		if err := comp.Compile(program); err != nil {
+			t.Fatalf("compiler error: %s", err)
+		}
+
+		machine := New(comp.Bytecode())
+		if err := machine.Run(); err != nil {
+			t.Fatalf("vm error: %s", err)
+		}
+
+		testExpectedObject(t, tt.expected, machine.LastPoppedStackElem())
+	}
+}
+
+func parse(input string) *ast.Program {
+	l := lexer.New(input)
+	p := parser.New(l)
+	return p.ParseProgram()
+}
+
+func testExpectedObject(t *testing.T, expected interface{}, actual object.Object) {
+	t.Helper()
+
+	switch expected := expected.(type) {
+	case int:
+		if err := testIntegerObject(int64(expected), actual); err != nil {
+			t.Errorf("testIntegerObject failed: %s", err)
+		}
+	case bool:
+		result, ok := actual.(*object.Boolean)
+		if !ok {
+			t.Errorf("object is not Boolean. got=%T (%+v)", actual, actual)
+			return
+		}
+		if result.Value != expected {
+			t.Errorf("object has wrong value. got=%t, want=%t", result.Value, expected)
+		}
+	case string:
+		result, ok := actual.(*object.String)
+		if !ok {
+			t.Errorf("object is not String. got=%T (%+v)", actual, actual)
+			return
+		}
+		if result.Value != expected {
+			t.Errorf("object has wrong value. got=%q, want=%q", result.Value, expected)
+		}
+	case *object.Null:
+		if actual != Null {
+			t.Errorf("object is not Null. got=%T (%+v)", actual, actual)
+		}
+	}
+}
+
+func testIntegerObject(expected int64, actual object.Object) error {
+	result, ok := actual.(*object.Integer)
+	if !ok {
+		return fmt.Errorf("object is not Integer. got=%T (%+v)", actual, actual)
+	}
+	if result.Value != expected {
+		return fmt.Errorf("object has wrong value. got=%d, want=%d", result.Value, expected)
+	}
+	return nil
+}